@@ -2,6 +2,9 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -11,12 +14,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 
 	otgrpc "github.com/opentracing-contrib/go-grpc"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 
@@ -24,8 +29,19 @@ import (
 	"github.com/rendau/jwts/internal/constant"
 	handlerGrpcP "github.com/rendau/jwts/internal/handler/grpc"
 	handlerHttpP "github.com/rendau/jwts/internal/handler/http"
+	callerServiceP "github.com/rendau/jwts/internal/service/caller/service"
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
 	"github.com/rendau/jwts/internal/service/jwk/e-jwk/kc"
+	"github.com/rendau/jwts/internal/service/jwk/e-jwk/oidc"
 	jwkServiceP "github.com/rendau/jwts/internal/service/jwk/service"
+	jwtModel "github.com/rendau/jwts/internal/service/jwt/model"
+	refresh_store "github.com/rendau/jwts/internal/service/jwt/refresh-store"
+	refreshStoreFile "github.com/rendau/jwts/internal/service/jwt/refresh-store/file"
+	refreshStoreMem "github.com/rendau/jwts/internal/service/jwt/refresh-store/mem"
+	revoke_store "github.com/rendau/jwts/internal/service/jwt/revoke-store"
+	revokeStoreFile "github.com/rendau/jwts/internal/service/jwt/revoke-store/file"
+	revokeStoreMem "github.com/rendau/jwts/internal/service/jwt/revoke-store/mem"
 	jwtServiceP "github.com/rendau/jwts/internal/service/jwt/service"
 	jwtsServiceP "github.com/rendau/jwts/internal/service/jwts/service"
 	jwkUsecaseP "github.com/rendau/jwts/internal/usecase/jwk"
@@ -33,8 +49,14 @@ import (
 	"github.com/rendau/jwts/pkg/proto/jwts_v1"
 )
 
+// upstreamStartupTimeout limits the first fetch of optional external jwks
+const upstreamStartupTimeout = 5 * time.Second
+
 type App struct {
-	jwkService *jwkServiceP.Service
+	jwkService    *jwkServiceP.Service
+	rotator       *jwtsServiceP.Rotator
+	keyReloader   *jwtsServiceP.KeyReloader
+	jwksRefresher *jwkServiceP.Refresher
 
 	grpcServer *grpc.Server
 	httpServer *http.Server
@@ -42,6 +64,11 @@ type App struct {
 	// globalTracer
 	globalTracerCloser io.Closer
 
+	// background jobs
+	jobsCtx       context.Context
+	jobsCtxCancel context.CancelFunc
+	jobsWg        sync.WaitGroup
+
 	exitCode int
 }
 
@@ -49,6 +76,26 @@ func (a *App) Init() {
 	var err error
 
 	var jwtsService *jwtsServiceP.Service
+	var keyLoader *jwtsServiceP.KeyLoader
+
+	var revokeStore revoke_store.RevokeStoreI
+	var refreshStore refresh_store.RefreshStoreI
+	var clientService *clientServiceP.Service
+	var callerService *callerServiceP.Service
+
+	var tlsConfig *tls.Config
+	var internalKey string // authenticates callers forwarded from http to grpc
+
+	// allowed issuers of created tokens, the first is the default
+	issuers := config.Conf.Issuers
+	if len(issuers) == 0 && config.Conf.DefaultIssuer != "" {
+		issuers = []string{config.Conf.DefaultIssuer}
+	}
+
+	var defaultIssuer string
+	if len(issuers) > 0 {
+		defaultIssuer = issuers[0]
+	}
 
 	var jwkHandlerGrpc *handlerGrpcP.Jwk
 	var jwtHandlerGrpc *handlerGrpcP.Jwt
@@ -72,52 +119,187 @@ func (a *App) Init() {
 
 	// jwts
 	{
-		jwtsService = jwtsServiceP.New(config.Conf.Kid)
-		if config.Conf.PublicPem != "" || config.Conf.PrivatePem != "" {
-			var privatePem []byte
-			var publicPem []byte
-
-			if privatePemPath := config.Conf.PrivatePem; privatePemPath != "" {
-				privatePem, err = os.ReadFile(privatePemPath)
-				if err != nil {
-					log.Fatal(err)
-				}
-			}
+		keyLoader = jwtsServiceP.NewKeyLoader(
+			config.Conf.Kid,
+			config.Conf.PrivatePem,
+			config.Conf.PublicPem,
+			config.Conf.KeyringFile,
+		)
+
+		keys, err := keyLoader.Load()
+		if err != nil {
+			log.Fatal(err)
+		}
 
-			if publicPemPath := config.Conf.PublicPem; publicPemPath != "" {
-				publicPem, err = os.ReadFile(publicPemPath)
-				if err != nil {
-					log.Fatal(err)
-				}
-			}
+		// set keys
+		jwtsService = jwtsServiceP.New()
+		err = jwtsService.SetKeys(keys)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// jwk
+	{
+		eJwkServices := []e_jwk.EJwkServiceI{
+			kc.New(config.Conf.KcURL, config.Conf.KcRealmName, config.Conf.KcIssuer),
+		}
 
-			// set keys
-			err = jwtsService.SetKeys(privatePem, publicPem)
+		for _, v := range config.Conf.OidcUpstreams {
+			eJwkOidc, err := oidc.ParseUpstream(v)
 			if err != nil {
 				log.Fatal(err)
 			}
+
+			eJwkServices = append(eJwkServices, eJwkOidc)
+		}
+
+		a.jwkService = jwkServiceP.New(jwtsService, eJwkServices)
+		usecase := jwkUsecaseP.New(a.jwkService)
+		jwkHandlerGrpc = handlerGrpcP.NewJwk(usecase)
+
+		if a.jwkService.HasExternal() {
+			var onRefresh func(err error)
+			if config.Conf.WithMetrics {
+				onRefresh = JwksRefreshMetrics(config.Conf.Namespace, constant.ServiceName)
+			}
+
+			a.jwksRefresher = jwkServiceP.NewRefresher(
+				a.jwkService,
+				config.Conf.JwksRefreshInterval,
+				config.Conf.JwksRefreshMinInterval,
+				onRefresh,
+			)
 		}
 	}
 
-	// jwk
+	// key reload
 	{
-		eJwkKC := kc.New(config.Conf.KcURL, config.Conf.KcRealmName)
+		if !keyLoader.IsEmpty() && config.Conf.KeysReloadInterval > 0 {
+			a.keyReloader = jwtsServiceP.NewKeyReloader(jwtsService, keyLoader, config.Conf.KeysReloadInterval, a.jwkService.UpdateKeys)
+		}
+	}
 
-		a.jwkService = jwkServiceP.New(jwtsService, eJwkKC)
-		usecase := jwkUsecaseP.New(a.jwkService)
-		jwkHandlerGrpc = handlerGrpcP.NewJwk(usecase)
+	// key rotation
+	{
+		if config.Conf.KeyRotationInterval > 0 {
+			a.rotator, err = jwtsServiceP.NewRotator(
+				jwtsService,
+				config.Conf.KeyRotationAlg,
+				config.Conf.KeyRotationInterval,
+				config.Conf.KeyRotationPrePublish,
+				config.Conf.KeyRotationRetireAfter,
+				config.Conf.KeyRotationDir,
+				a.jwkService.UpdateKeys,
+			)
+			errCheck(err, "jwtsServiceP.NewRotator")
+
+			err = a.rotator.Load()
+			errCheck(err, "rotator.Load")
+		}
+	}
+
+	// revoke store
+	{
+		if config.Conf.RevocationFile != "" {
+			revokeStore, err = revokeStoreFile.New(config.Conf.RevocationFile)
+			errCheck(err, "revokeStoreFile.New")
+		} else {
+			revokeStore = revokeStoreMem.New()
+		}
+	}
+
+	// refresh store
+	{
+		if config.Conf.RefreshFile != "" {
+			refreshStore, err = refreshStoreFile.New(config.Conf.RefreshFile)
+			errCheck(err, "refreshStoreFile.New")
+		} else {
+			refreshStore = refreshStoreMem.New()
+		}
+	}
+
+	// client
+	{
+		clients, err := clientServiceP.ParseClients(config.Conf.IntrospectClients)
+		errCheck(err, "clientServiceP.ParseClients")
+
+		if config.Conf.ClientsFile != "" {
+			fileClients, err := clientServiceP.LoadClients(config.Conf.ClientsFile)
+			errCheck(err, "clientServiceP.LoadClients")
+
+			clients = append(clients, fileClients...)
+		}
+
+		clientService = clientServiceP.New(clients, config.Conf.OAuthTokenTtl)
 	}
 
 	// jwt
 	{
-		jwtService := jwtServiceP.New(jwtsService, config.Conf.DefaultIssuer)
+		var mintPolicies []*jwtModel.MintPolicy
+		if config.Conf.MintPoliciesFile != "" {
+			mintPolicies, err = jwtServiceP.LoadMintPolicies(config.Conf.MintPoliciesFile)
+			errCheck(err, "jwtServiceP.LoadMintPolicies")
+		}
+
+		jwtService := jwtServiceP.New(jwtServiceP.Options{
+			JwtsService:   jwtsService,
+			JwkService:    a.jwkService,
+			RevokeStore:   revokeStore,
+			RefreshStore:  refreshStore,
+			ClientService: clientService,
+			Issuers:       issuers,
+			DefaultPolicy: jwtModel.ValidatePolicy{
+				Issuers:        config.Conf.ValidateIssuers,
+				Audiences:      config.Conf.ValidateAudiences,
+				RequiredClaims: config.Conf.ValidateRequiredClaims,
+				Leeway:         config.Conf.ValidateLeeway,
+				MaxAge:         config.Conf.ValidateMaxAge,
+				Strict:         &config.Conf.ValidateStrict,
+				Typ:            config.Conf.ValidateTyp,
+			},
+			RefreshTtl:   config.Conf.RefreshTokenTtl,
+			MintPolicies: mintPolicies,
+			ExtraHeaders: config.Conf.CreateHeaders,
+			CritHeaders:  config.Conf.CritHeaders,
+		})
 		usecase := jwtUsecaseP.New(jwtService)
 		jwtHandlerGrpc = handlerGrpcP.NewJwt(usecase)
 	}
 
+	// caller
+	{
+		apiKeys, err := callerServiceP.ParseApiKeys(config.Conf.ApiKeys)
+		errCheck(err, "callerServiceP.ParseApiKeys")
+
+		if config.Conf.ApiKeysFile != "" {
+			fileApiKeys, err := callerServiceP.LoadApiKeys(config.Conf.ApiKeysFile)
+			errCheck(err, "callerServiceP.LoadApiKeys")
+
+			apiKeys = append(apiKeys, fileApiKeys...)
+		}
+
+		tlsConfig, err = loadTlsConfig(config.Conf.TlsCert, config.Conf.TlsKey, config.Conf.TlsClientCa)
+		errCheck(err, "loadTlsConfig")
+
+		callerService = callerServiceP.New(apiKeys, tlsConfig != nil && tlsConfig.ClientCAs != nil)
+
+		if config.Conf.AuthDisabled {
+			slog.Warn("caller authentication is disabled, anyone can mint tokens")
+		} else if !callerService.Enabled() {
+			errCheck(errors.New("api keys or client CA are required, set AUTH_DISABLED=true to run without caller authentication"), "")
+		}
+
+		internalKeyBytes := make([]byte, 32)
+		_, err = rand.Read(internalKeyBytes)
+		errCheck(err, "rand.Read")
+
+		internalKey = hex.EncodeToString(internalKeyBytes)
+	}
+
 	// grpc server
 	{
-		interceptors := make([]grpc.UnaryServerInterceptor, 0, 3)
+		interceptors := make([]grpc.UnaryServerInterceptor, 0, 4)
 
 		// tracing
 		interceptors = append(interceptors, GrpcInterceptorTracing(opentracing.GlobalTracer()))
@@ -131,10 +313,28 @@ func (a *App) Init() {
 		// error
 		interceptors = append(interceptors, GrpcInterceptorError())
 
+		// caller authentication
+		if !config.Conf.AuthDisabled {
+			interceptors = append(interceptors, GrpcInterceptorAuth(callerService, internalKey, []string{
+				jwts_v1.Jwt_Create_FullMethodName,
+				jwts_v1.Jwt_Exchange_FullMethodName,
+				jwts_v1.Jwt_Revoke_FullMethodName,
+				jwts_v1.Jwt_RevokeSubject_FullMethodName,
+				jwts_v1.Jwt_ListSubjectCutoffs_FullMethodName,
+				jwts_v1.Jwt_ClearSubjectCutoff_FullMethodName,
+			}))
+		}
+
+		serverOpts := []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(interceptors...),
+		}
+
+		if tlsConfig != nil {
+			serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
 		// server
-		a.grpcServer = grpc.NewServer(grpc.ChainUnaryInterceptor(
-			interceptors...,
-		))
+		a.grpcServer = grpc.NewServer(serverOpts...)
 
 		// register grpc handlers
 		jwts_v1.RegisterJwkServer(a.grpcServer, jwkHandlerGrpc)
@@ -146,15 +346,24 @@ func (a *App) Init() {
 
 	// http server
 	{
+		transportCredentials := insecure.NewCredentials()
+		if tlsConfig != nil {
+			// loopback connection to own grpc server
+			transportCredentials = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+		}
+
 		opts := []grpc.DialOption{
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithTransportCredentials(transportCredentials),
 			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(1024 * 1024 * 1024)),
-			grpc.WithUnaryInterceptor(otgrpc.OpenTracingClientInterceptor(
-				opentracing.GlobalTracer(),
-				otgrpc.IncludingSpans(func(parentSpanCtx opentracing.SpanContext, method string, req, resp any) bool {
-					return parentSpanCtx != nil // only include spans if there is a parent span
-				}),
-			)),
+			grpc.WithChainUnaryInterceptor(
+				otgrpc.OpenTracingClientInterceptor(
+					opentracing.GlobalTracer(),
+					otgrpc.IncludingSpans(func(parentSpanCtx opentracing.SpanContext, method string, req, resp any) bool {
+						return parentSpanCtx != nil // only include spans if there is a parent span
+					}),
+				),
+				GrpcClientInterceptorCaller(internalKey),
+			),
 		}
 		conn, err := grpc.DialContext(context.Background(), "localhost:"+config.Conf.GrpcPort, opts...)
 		errCheck(err, "grpc.DialContext")
@@ -162,7 +371,28 @@ func (a *App) Init() {
 		grpcJwkClient := jwts_v1.NewJwkClient(conn)
 		grpcJwtClient := jwts_v1.NewJwtClient(conn)
 
-		handlerHttp := handlerHttpP.New(grpcJwkClient, grpcJwtClient)
+		jwksMaxAge := config.Conf.JwksMaxAge
+		if a.rotator != nil {
+			jwksMaxAge = min(jwksMaxAge, a.rotator.CacheMaxAge())
+		}
+
+		trustedProxies, err := handlerHttpP.ParseTrustedProxies(config.Conf.TrustedProxies)
+		errCheck(err, "handlerHttpP.ParseTrustedProxies")
+
+		if defaultIssuer == "" {
+			slog.Warn("discovery is disabled, ISSUERS or DEFAULT_ISSUER is not set")
+		}
+
+		handlerHttp := handlerHttpP.New(
+			grpcJwkClient,
+			grpcJwtClient,
+			jwtsService,
+			clientService,
+			defaultIssuer,
+			config.Conf.PublicUrl,
+			trustedProxies,
+			jwksMaxAge,
+		)
 
 		mux := http.NewServeMux()
 
@@ -170,6 +400,19 @@ func (a *App) Init() {
 		mux.HandleFunc("GET /jwk/set", handlerHttp.JwkGetSet)
 		mux.HandleFunc("POST /jwt", handlerHttp.JwtCreate)
 		mux.HandleFunc("PUT /jwt/validate", handlerHttp.JwtValidate)
+		mux.HandleFunc("POST /jwt/refresh", handlerHttp.JwtRefresh)
+		mux.HandleFunc("POST /jwt/revoke", handlerHttp.JwtRevoke)
+		mux.HandleFunc("POST /jwt/revoke/subject", handlerHttp.JwtRevokeSubject)
+		mux.HandleFunc("GET /jwt/revoke/subject", handlerHttp.JwtListSubjectCutoffs)
+		mux.HandleFunc("DELETE /jwt/revoke/subject/{sub}", handlerHttp.JwtClearSubjectCutoff)
+		mux.HandleFunc("POST /introspect", handlerHttp.Introspect)
+		mux.HandleFunc("POST /revoke", handlerHttp.OAuthRevoke)
+		mux.HandleFunc("POST /oauth/token", handlerHttp.OAuthToken)
+
+		// well-known
+		mux.HandleFunc("GET /.well-known/jwks.json", handlerHttp.JwkGetSet)
+		mux.HandleFunc("GET /.well-known/openid-configuration", handlerHttp.OpenidConfiguration)
+		mux.HandleFunc("GET /.well-known/oauth-authorization-server", handlerHttp.OpenidConfiguration)
 
 		// metrics
 		mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
@@ -179,9 +422,13 @@ func (a *App) Init() {
 		// healthcheck
 		mux.HandleFunc("GET /healthcheck", func(w http.ResponseWriter, r *http.Request) {})
 
+		// readiness
+		mux.HandleFunc("GET /readiness", HttpReadinessHandler(a.jwkService, config.Conf.UpstreamRequired))
+
 		a.httpServer = &http.Server{
 			Addr:              ":" + config.Conf.HttpPort,
-			Handler:           HttpMiddlewares(mux),
+			Handler:           HttpMiddlewares(HttpCallerMiddleware(callerService, mux)),
+			TLSConfig:         tlsConfig,
 			ReadHeaderTimeout: 2 * time.Second,
 			ReadTimeout:       time.Minute,
 			MaxHeaderBytes:    300 * 1024,
@@ -192,15 +439,49 @@ func (a *App) Init() {
 func (a *App) PreStartHook() {
 	slog.Info("PreStartHook")
 
-	err := a.jwkService.CreateJwks()
-	errCheck(err, "jwkService.CreateJwks")
+	err := a.jwkService.UpdateKeys()
+	errCheck(err, "jwkService.UpdateKeys")
+
+	// optional upstreams must not hold the startup, the refresher keeps fetching them
+	ctx := context.Background()
+	if !config.Conf.UpstreamRequired {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, upstreamStartupTimeout)
+		defer cancel()
+	}
+
+	_, err = a.jwkService.RefreshExternalKeys(ctx)
+	if err != nil {
+		if config.Conf.UpstreamRequired {
+			errCheck(err, "jwkService.RefreshExternalKeys")
+		}
+
+		// degraded mode: local keys are served, the refresher keeps retrying
+		slog.Warn("external jwks unavailable, starting without them", "error", err)
+	}
 }
 
 func (a *App) Start() {
 	slog.Info("Starting")
 
+	a.jobsCtx, a.jobsCtxCancel = context.WithCancel(context.Background())
+
 	// services
 	{
+		if a.rotator != nil {
+			slog.Info("key rotation enabled")
+			a.jobsWg.Go(func() { a.rotator.Run(a.jobsCtx) })
+		}
+
+		if a.keyReloader != nil {
+			slog.Info("key files reload enabled")
+			a.jobsWg.Go(func() { a.keyReloader.Run(a.jobsCtx) })
+		}
+
+		if a.jwksRefresher != nil {
+			slog.Info("external jwks refresh enabled")
+			a.jobsWg.Go(func() { a.jwksRefresher.Run(a.jobsCtx) })
+		}
 	}
 
 	// grpc server
@@ -217,7 +498,12 @@ func (a *App) Start() {
 	// http server
 	{
 		go func() {
-			err := a.httpServer.ListenAndServe()
+			var err error
+			if a.httpServer.TLSConfig != nil {
+				err = a.httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = a.httpServer.ListenAndServe()
+			}
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
 				errCheck(err, "http-server stopped")
 			}
@@ -252,10 +538,17 @@ func (a *App) Stop() {
 	{
 		a.grpcServer.GracefulStop()
 	}
+
+	// background jobs
+	{
+		a.jobsCtxCancel()
+	}
 }
 
 func (a *App) WaitJobs() {
 	slog.Info("waiting jobs")
+
+	a.jobsWg.Wait()
 }
 
 func (a *App) Exit() {
@@ -2,8 +2,14 @@ package app
 
 import (
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"slices"
 	"time"
 
 	otgrpc "github.com/opentracing-contrib/go-grpc"
@@ -12,10 +18,14 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/protoadapt"
 
 	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	callerServiceP "github.com/rendau/jwts/internal/service/caller/service"
 	"github.com/rendau/jwts/pkg/proto/common"
 )
 
@@ -80,7 +90,7 @@ func GrpcInterceptorError() grpc.UnaryServerInterceptor {
 			return h, nil
 		}
 
-		var ei protoadapt.MessageV1
+		var ei *common.ErrorRep
 		errStr := err.Error()
 
 		var errBase errs.Err
@@ -114,7 +124,9 @@ func GrpcInterceptorError() grpc.UnaryServerInterceptor {
 			}
 		}
 
-		st := status.New(codes.InvalidArgument, errStr)
+		code := grpcErrorCode(ei.Code)
+
+		st := status.New(code, errStr)
 		st, err = st.WithDetails(ei)
 		if err != nil {
 			slog.Error(
@@ -122,9 +134,134 @@ func GrpcInterceptorError() grpc.UnaryServerInterceptor {
 				slog.String("error", errStr),
 				slog.String("method", info.FullMethod),
 			)
-			st = status.New(codes.InvalidArgument, errStr)
+			st = status.New(code, errStr)
 		}
 
 		return h, st.Err()
 	}
 }
+
+// grpcErrorCode maps caller authentication errors to their grpc codes, others are invalid arguments
+func grpcErrorCode(errCode string) codes.Code {
+	switch errCode {
+	case errs.Unauthenticated.Error():
+		return codes.Unauthenticated
+	case errs.PermissionDenied.Error():
+		return codes.PermissionDenied
+	}
+
+	return codes.InvalidArgument
+}
+
+// metadata keys of the caller forwarded by the http layer
+const (
+	mdApiKey       = "x-api-key"
+	mdInternalKey  = "x-jwts-internal-key"
+	mdCallerId     = "x-jwts-caller-id"
+	mdCallerMethod = "x-jwts-caller-method"
+)
+
+// GrpcInterceptorAuth requires an authenticated caller for the given methods:
+// api key in "x-api-key" metadata, client certificate verified by tls, or a caller forwarded by the http layer
+func GrpcInterceptorAuth(callerService *callerServiceP.Service, internalKey string, methods []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if !slices.Contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		caller := grpcCaller(ctx, callerService, internalKey)
+		if caller == nil {
+			return nil, errs.ErrFull{Err: errs.Unauthenticated, Desc: "api key or client certificate is required"}
+		}
+
+		return handler(callerModel.NewContext(ctx, caller), req)
+	}
+}
+
+func grpcCaller(ctx context.Context, callerService *callerServiceP.Service, internalKey string) *callerModel.Caller {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if key := grpcMetadataValue(md, mdApiKey); key != "" {
+		return callerService.AuthenticateKey(key)
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if caller := callerService.AuthenticateCert(tlsInfo.State.VerifiedChains); caller != nil {
+				return caller
+			}
+		}
+	}
+
+	forwardedKey := grpcMetadataValue(md, mdInternalKey)
+	if forwardedKey != "" && subtle.ConstantTimeCompare([]byte(forwardedKey), []byte(internalKey)) == 1 {
+		if id := grpcMetadataValue(md, mdCallerId); id != "" {
+			return &callerModel.Caller{
+				Id:     id,
+				Method: grpcMetadataValue(md, mdCallerMethod),
+			}
+		}
+	}
+
+	return nil
+}
+
+func grpcMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// GrpcClientInterceptorCaller forwards the caller authenticated by the http layer
+func GrpcClientInterceptorCaller(internalKey string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if caller := callerModel.FromContext(ctx); caller != nil {
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				mdInternalKey, internalKey,
+				mdCallerId, caller.Id,
+				mdCallerMethod, caller.Method,
+			)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// loadTlsConfig loads the server certificate and the CA of client certificates, nil if tls is not configured
+func loadTlsConfig(certFile, keyFile, clientCaFile string) (*tls.Config, error) {
+	if certFile == "" {
+		if clientCaFile != "" {
+			return nil, fmt.Errorf("client CA requires server certificate")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+
+	result := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCaFile != "" {
+		caPem, err := os.ReadFile(clientCaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ClientCAs = x509.NewCertPool()
+		if !result.ClientCAs.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("no certificates in client CA file")
+		}
+
+		// callers without certificate can authenticate with api keys
+		result.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return result, nil
+}
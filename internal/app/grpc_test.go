@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	callerServiceP "github.com/rendau/jwts/internal/service/caller/service"
+)
+
+func TestGrpcInterceptorAuth(t *testing.T) {
+	keyHash := sha256.Sum256([]byte("key-1"))
+
+	apiKeys, err := callerServiceP.ParseApiKeys([]string{"backend:" + hex.EncodeToString(keyHash[:])})
+	require.NoError(t, err)
+
+	interceptor := GrpcInterceptorAuth(callerServiceP.New(apiKeys, false), "internal", []string{"/svc/Protected"})
+
+	call := func(method string, md ...string) (*callerModel.Caller, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(md...))
+
+		var caller *callerModel.Caller
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			caller = callerModel.FromContext(ctx)
+			return nil, nil
+		})
+
+		return caller, err
+	}
+
+	_, err = call("/svc/Public")
+	require.NoError(t, err)
+
+	_, err = call("/svc/Protected")
+	require.Error(t, err)
+
+	_, err = call("/svc/Protected", mdApiKey, "wrong")
+	require.Error(t, err)
+
+	caller, err := call("/svc/Protected", mdApiKey, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, &callerModel.Caller{Id: "backend", Method: callerModel.MethodApiKey}, caller)
+
+	// forwarded by the http layer
+	_, err = call("/svc/Protected", mdInternalKey, "forged", mdCallerId, "admin")
+	require.Error(t, err)
+
+	caller, err = call("/svc/Protected", mdInternalKey, "internal", mdCallerId, "gateway", mdCallerMethod, callerModel.MethodClient)
+	require.NoError(t, err)
+	require.Equal(t, &callerModel.Caller{Id: "gateway", Method: callerModel.MethodClient}, caller)
+}
+
+func TestGrpcInterceptorError(t *testing.T) {
+	interceptor := GrpcInterceptorError()
+
+	call := func(err error) codes.Code {
+		_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, func(ctx context.Context, req any) (any, error) {
+			return nil, err
+		})
+
+		return status.Code(err)
+	}
+
+	require.Equal(t, codes.Unauthenticated, call(errs.ErrFull{Err: errs.Unauthenticated, Desc: "api key or client certificate is required"}))
+	require.Equal(t, codes.PermissionDenied, call(fmt.Errorf("srv.Create: %w", errs.ErrFull{Err: errs.PermissionDenied})))
+	require.Equal(t, codes.InvalidArgument, call(errs.InvalidToken))
+	require.Equal(t, codes.InvalidArgument, call(errors.New("unknown")))
+}
@@ -1,14 +1,19 @@
 package app
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rs/cors"
 
 	"github.com/rendau/jwts/internal/config"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	callerServiceP "github.com/rendau/jwts/internal/service/caller/service"
+	jwkServiceP "github.com/rendau/jwts/internal/service/jwk/service"
 )
 
 func HttpMiddlewares(handler http.Handler) http.Handler {
@@ -48,6 +53,7 @@ func HttpMiddlewares(handler http.Handler) http.Handler {
 				"Content-Type",
 				"X-Requested-With",
 				"Authorization",
+				"X-Api-Key",
 			},
 			AllowCredentials: true,
 			MaxAge:           604800,
@@ -70,3 +76,75 @@ func HttpMiddlewares(handler http.Handler) http.Handler {
 
 	return handler
 }
+
+// HttpCallerMiddleware authenticates the caller by "X-Api-Key" header or the verified client certificate,
+// the caller is forwarded to grpc
+func HttpCallerMiddleware(callerService *callerServiceP.Service, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var caller *callerModel.Caller
+
+		if key := r.Header.Get("X-Api-Key"); key != "" {
+			caller = callerService.AuthenticateKey(key)
+		} else if r.TLS != nil {
+			caller = callerService.AuthenticateCert(r.TLS.VerifiedChains)
+		}
+
+		if caller != nil {
+			r = r.WithContext(callerModel.NewContext(r.Context(), caller))
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+type readinessRep struct {
+	Ready     bool                   `json:"ready"`
+	Upstreams []readinessUpstreamRep `json:"upstreams"`
+}
+
+type readinessUpstreamRep struct {
+	Name          string     `json:"name"`
+	Issuer        string     `json:"issuer,omitempty"`
+	Healthy       bool       `json:"healthy"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// HttpReadinessHandler reports upstream jwks health.
+// Unhealthy upstreams make the service not ready only if they are required
+func HttpReadinessHandler(jwkService *jwkServiceP.Service, upstreamRequired bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rep := readinessRep{
+			Ready:     true,
+			Upstreams: make([]readinessUpstreamRep, 0),
+		}
+
+		for _, item := range jwkService.GetUpstreamStatus() {
+			upstream := readinessUpstreamRep{
+				Name:      item.Name,
+				Issuer:    item.Issuer,
+				Healthy:   item.Healthy,
+				LastError: item.LastError,
+			}
+
+			if !item.LastSuccessAt.IsZero() {
+				upstream.LastSuccessAt = &item.LastSuccessAt
+			}
+
+			if !item.Healthy && upstreamRequired {
+				rep.Ready = false
+			}
+
+			rep.Upstreams = append(rep.Upstreams, upstream)
+		}
+
+		status := http.StatusOK
+		if !rep.Ready {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(rep)
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+func JwksRefreshMetrics(namespace, service string) func(err error) {
+	refreshCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jwks",
+		Name:      service + "_refresh_count",
+	}, []string{
+		"status",
+	})
+
+	lastSuccessGauge := promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "jwks",
+		Name:      service + "_refresh_last_success_timestamp_seconds",
+	})
+
+	return func(err error) {
+		if err != nil {
+			refreshCounter.WithLabelValues("error").Inc()
+			return
+		}
+
+		refreshCounter.WithLabelValues("ok").Inc()
+		lastSuccessGauge.Set(float64(time.Now().Unix()))
+	}
+}
@@ -1,25 +1,65 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v9"
 	_ "github.com/joho/godotenv/autoload"
 )
 
 var Conf = struct {
-	Namespace     string `env:"NAMESPACE" envDefault:"example.com"`
-	Debug         bool   `env:"DEBUG" envDefault:"false"`
-	GrpcPort      string `env:"GRPC_PORT" envDefault:"5050"`
-	HttpPort      string `env:"HTTP_PORT" envDefault:"80"`
-	HttpCors      bool   `env:"HTTP_CORS" envDefault:"false"`
-	WithMetrics   bool   `env:"WITH_METRICS" envDefault:"false"`
-	WithTracing   bool   `env:"WITH_TRACING" envDefault:"false"`
-	JaegerAddress string `env:"JAEGER_ADDRESS"`
-	Kid           string `env:"KID"`
-	DefaultIssuer string `env:"DEFAULT_ISSUER"`
-	PrivatePem    string `env:"PRIVATE_PEM"`
-	PublicPem     string `env:"PUBLIC_PEM"`
-	KcURL         string `env:"KC_URL"`
-	KcRealmName   string `env:"KC_REALM_NAME"`
+	Namespace              string        `env:"NAMESPACE" envDefault:"example.com"`
+	Debug                  bool          `env:"DEBUG" envDefault:"false"`
+	GrpcPort               string        `env:"GRPC_PORT" envDefault:"5050"`
+	HttpPort               string        `env:"HTTP_PORT" envDefault:"80"`
+	HttpCors               bool          `env:"HTTP_CORS" envDefault:"false"`
+	WithMetrics            bool          `env:"WITH_METRICS" envDefault:"false"`
+	WithTracing            bool          `env:"WITH_TRACING" envDefault:"false"`
+	JaegerAddress          string        `env:"JAEGER_ADDRESS"`
+	Kid                    string        `env:"KID"`
+	DefaultIssuer          string        `env:"DEFAULT_ISSUER"`
+	Issuers                []string      `env:"ISSUERS"`
+	PublicUrl              string        `env:"PUBLIC_URL"`
+	TrustedProxies         []string      `env:"TRUSTED_PROXIES"`
+	PrivatePem             string        `env:"PRIVATE_PEM"`
+	PublicPem              string        `env:"PUBLIC_PEM"`
+	KeyringFile            string        `env:"KEYRING_FILE"`
+	KeysReloadInterval     time.Duration `env:"KEYS_RELOAD_INTERVAL" envDefault:"30s"`
+	KeyRotationInterval    time.Duration `env:"KEY_ROTATION_INTERVAL"`
+	KeyRotationAlg         string        `env:"KEY_ROTATION_ALG" envDefault:"RS256"`
+	KeyRotationPrePublish  time.Duration `env:"KEY_ROTATION_PRE_PUBLISH" envDefault:"1h"`
+	KeyRotationRetireAfter time.Duration `env:"KEY_ROTATION_RETIRE_AFTER" envDefault:"720h"`
+	KeyRotationDir         string        `env:"KEY_ROTATION_DIR"`
+	KcURL                  string        `env:"KC_URL"`
+	KcRealmName            string        `env:"KC_REALM_NAME"`
+	KcIssuer               string        `env:"KC_ISSUER"`
+	OidcUpstreams          []string      `env:"OIDC_UPSTREAMS"`
+	JwksRefreshInterval    time.Duration `env:"JWKS_REFRESH_INTERVAL" envDefault:"5m"`
+	JwksMaxAge             time.Duration `env:"JWKS_MAX_AGE" envDefault:"5m"`
+	JwksRefreshMinInterval time.Duration `env:"JWKS_REFRESH_MIN_INTERVAL" envDefault:"30s"`
+	UpstreamRequired       bool          `env:"UPSTREAM_REQUIRED" envDefault:"false"`
+	ValidateIssuers        []string      `env:"VALIDATE_ISSUERS"`
+	ValidateAudiences      []string      `env:"VALIDATE_AUDIENCES"`
+	ValidateRequiredClaims []string      `env:"VALIDATE_REQUIRED_CLAIMS"`
+	ValidateLeeway         time.Duration `env:"VALIDATE_LEEWAY"`
+	ValidateMaxAge         time.Duration `env:"VALIDATE_MAX_AGE"`
+	ValidateStrict         bool          `env:"VALIDATE_STRICT" envDefault:"false"`
+	ValidateTyp            string        `env:"VALIDATE_TYP"`
+	CreateHeaders          []string      `env:"CREATE_HEADERS"`
+	CritHeaders            []string      `env:"CRIT_HEADERS"`
+	RevocationFile         string        `env:"REVOCATION_FILE"`
+	RefreshFile            string        `env:"REFRESH_FILE"`
+	RefreshTokenTtl        time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	IntrospectClients      []string      `env:"INTROSPECT_CLIENTS"`
+	ClientsFile            string        `env:"CLIENTS_FILE"`
+	OAuthTokenTtl          time.Duration `env:"OAUTH_TOKEN_TTL" envDefault:"1h"`
+	ApiKeys                []string      `env:"API_KEYS"`
+	ApiKeysFile            string        `env:"API_KEYS_FILE"`
+	TlsCert                string        `env:"TLS_CERT"`
+	TlsKey                 string        `env:"TLS_KEY"`
+	TlsClientCa            string        `env:"TLS_CLIENT_CA"`
+	AuthDisabled           bool          `env:"AUTH_DISABLED" envDefault:"false"`
+	MintPoliciesFile       string        `env:"MINT_POLICIES_FILE"`
 }{}
 
 func init() {
@@ -2,6 +2,4 @@ package constant
 
 const (
 	ServiceName = "jwts"
-
-	JwtSigningMethod = "RS256"
 )
@@ -7,8 +7,17 @@ func (e Err) Error() string {
 }
 
 const (
-	InvalidToken = Err("invalid_token")
-	ServiceNA    = Err("service_not_available")
+	InvalidToken     = Err("invalid_token")
+	ServiceNA        = Err("service_not_available")
+	Unauthenticated  = Err("unauthenticated")
+	PermissionDenied = Err("permission_denied")
+
+	// OAuth 2.0 error codes
+	InvalidRequest     = Err("invalid_request")
+	InvalidGrant       = Err("invalid_grant")
+	InvalidScope       = Err("invalid_scope")
+	InvalidTarget      = Err("invalid_target")
+	UnauthorizedClient = Err("unauthorized_client")
 )
 
 // ErrFull
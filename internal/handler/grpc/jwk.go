@@ -32,6 +32,9 @@ func (h *Jwk) Get(ctx context.Context, pars *emptypb.Empty) (*jwts_v1.JwkSet, er
 			Alg: key.Alg,
 			N:   key.N,
 			Use: key.Use,
+			Crv: key.Crv,
+			X:   key.X,
+			Y:   key.Y,
 		}
 	}
 
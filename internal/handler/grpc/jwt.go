@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
 	"github.com/rendau/jwts/internal/service/jwt/model"
 	usecase "github.com/rendau/jwts/internal/usecase/jwt"
 	"github.com/rendau/jwts/pkg/proto/jwts_v1"
@@ -30,23 +34,100 @@ func (h *Jwt) Create(ctx context.Context, req *jwts_v1.JwtCreateReq) (*jwts_v1.J
 		}
 	}
 
-	res, err := h.usecase.Create(&model.JwtCreateReq{
-		Sub:        req.Sub,
-		ExpSeconds: req.ExpSeconds,
-		Payload:    payload,
+	var header map[string]any
+	if len(req.Header) > 0 {
+		err := json.Unmarshal(req.Header, &header)
+		if err != nil {
+			return nil, fmt.Errorf("json.Unmarshal header: %w", err)
+		}
+	}
+
+	obj := &model.JwtCreateReq{
+		Caller:      callerModel.FromContext(ctx),
+		Sub:         req.Sub,
+		ExpSeconds:  req.ExpSeconds,
+		NbfSeconds:  req.NbfSeconds,
+		Iss:         req.Iss,
+		Aud:         req.Aud,
+		Jti:         req.Jti,
+		Payload:     payload,
+		Typ:         req.Typ,
+		Cty:         req.Cty,
+		Crit:        req.Crit,
+		Header:      header,
+		WithRefresh: req.WithRefresh,
+		RefreshTtl:  time.Duration(req.RefreshExpSeconds) * time.Second,
+	}
+
+	if req.Exp != 0 {
+		obj.Exp = time.Unix(req.Exp, 0)
+	}
+
+	if req.Nbf != 0 {
+		obj.Nbf = time.Unix(req.Nbf, 0)
+	}
+
+	if req.Iat != 0 {
+		obj.Iat = time.Unix(req.Iat, 0)
+	}
+
+	res, err := h.usecase.Create(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwts_v1.JwtCreateRep{
+		Token:        res.Token,
+		RefreshToken: res.RefreshToken,
+	}, nil
+}
+
+func (h *Jwt) Refresh(ctx context.Context, req *jwts_v1.JwtRefreshReq) (*jwts_v1.JwtCreateRep, error) {
+	res, err := h.usecase.Refresh(&model.JwtRefreshReq{
+		RefreshToken: req.RefreshToken,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &jwts_v1.JwtCreateRep{
-		Token: res.Token,
+		Token:        res.Token,
+		RefreshToken: res.RefreshToken,
+	}, nil
+}
+
+func (h *Jwt) Exchange(ctx context.Context, req *jwts_v1.JwtExchangeReq) (*jwts_v1.JwtExchangeRep, error) {
+	res, err := h.usecase.Exchange(&model.JwtExchangeReq{
+		Caller:       callerModel.FromContext(ctx),
+		SubjectToken: req.SubjectToken,
+		ActorToken:   req.ActorToken,
+		Audiences:    req.Audiences,
+		Scopes:       req.Scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwts_v1.JwtExchangeRep{
+		Token:      res.Token,
+		ExpSeconds: res.ExpSeconds,
+		Scopes:     res.Scopes,
 	}, nil
 }
 
 func (h *Jwt) Validate(ctx context.Context, req *jwts_v1.JwtValidateReq) (*jwts_v1.JwtValidateRep, error) {
 	res, err := h.usecase.Validate(&model.JwtValidateReq{
-		Token: req.Token,
+		Token:                   req.Token,
+		IncludeUnverifiedClaims: req.IncludeUnverifiedClaims,
+		ValidatePolicy: model.ValidatePolicy{
+			Issuers:        req.Issuers,
+			Audiences:      req.Audiences,
+			RequiredClaims: req.RequiredClaims,
+			Leeway:         time.Duration(req.LeewaySeconds) * time.Second,
+			MaxAge:         time.Duration(req.MaxAgeSeconds) * time.Second,
+			Strict:         req.Strict,
+			Typ:            req.Typ,
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -60,8 +141,100 @@ func (h *Jwt) Validate(ctx context.Context, req *jwts_v1.JwtValidateReq) (*jwts_
 		}
 	}
 
+	jsonUnverifiedClaims := make([]byte, 0)
+	if res.UnverifiedClaims != nil {
+		jsonUnverifiedClaims, err = json.Marshal(res.UnverifiedClaims)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal unverified claims: %w", err)
+		}
+	}
+
 	return &jwts_v1.JwtValidateRep{
-		Valid:  res.Valid,
-		Claims: jsonClaims,
+		Valid:            res.Valid,
+		Claims:           jsonClaims,
+		Reason:           invalidReasons[res.Reason],
+		Detail:           res.Detail,
+		UnverifiedClaims: jsonUnverifiedClaims,
 	}, nil
 }
+
+func (h *Jwt) Revoke(ctx context.Context, req *jwts_v1.JwtRevokeReq) (*emptypb.Empty, error) {
+	obj := &model.JwtRevokeReq{
+		Jti:   req.Jti,
+		Token: req.Token,
+	}
+
+	if req.Exp > 0 {
+		obj.ExpiresAt = time.Unix(req.Exp, 0)
+	}
+
+	err := h.usecase.Revoke(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Jwt) RevokeSubject(ctx context.Context, req *jwts_v1.JwtRevokeSubjectReq) (*emptypb.Empty, error) {
+	obj := &model.SubjectCutoff{
+		Sub: req.Sub,
+	}
+
+	if req.Before > 0 {
+		obj.Before = time.Unix(req.Before, 0)
+	}
+
+	err := h.usecase.RevokeSubject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Jwt) ListSubjectCutoffs(ctx context.Context, req *emptypb.Empty) (*jwts_v1.JwtSubjectCutoffList, error) {
+	items, err := h.usecase.ListSubjectCutoffs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &jwts_v1.JwtSubjectCutoffList{
+		Items: make([]*jwts_v1.JwtSubjectCutoff, 0, len(items)),
+	}
+
+	for _, item := range items {
+		result.Items = append(result.Items, &jwts_v1.JwtSubjectCutoff{
+			Sub:    item.Sub,
+			Before: item.Before.Unix(),
+		})
+	}
+
+	return result, nil
+}
+
+func (h *Jwt) ClearSubjectCutoff(ctx context.Context, req *jwts_v1.JwtClearSubjectCutoffReq) (*emptypb.Empty, error) {
+	err := h.usecase.ClearSubjectCutoff(req.Sub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+var invalidReasons = map[model.InvalidReason]jwts_v1.JwtInvalidReason{
+	model.InvalidReasonMalformed:       jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_MALFORMED,
+	model.InvalidReasonBadSignature:    jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_BAD_SIGNATURE,
+	model.InvalidReasonUnknownKid:      jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_UNKNOWN_KID,
+	model.InvalidReasonAlgMismatch:     jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_ALG_MISMATCH,
+	model.InvalidReasonExpired:         jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_EXPIRED,
+	model.InvalidReasonNotYetValid:     jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_NOT_YET_VALID,
+	model.InvalidReasonInvalidIssuer:   jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_INVALID_ISSUER,
+	model.InvalidReasonInvalidAudience: jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_INVALID_AUDIENCE,
+	model.InvalidReasonMissingClaim:    jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_MISSING_CLAIM,
+	model.InvalidReasonTooOld:          jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_TOO_OLD,
+	model.InvalidReasonInvalid:         jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_INVALID,
+	model.InvalidReasonRevoked:         jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_REVOKED,
+	model.InvalidReasonInvalidType:     jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_INVALID_TYPE,
+	model.InvalidReasonUnsupportedCrit: jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_UNSUPPORTED_CRIT,
+}
@@ -1,12 +1,18 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"strconv"
+	"strings"
+	"time"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -15,14 +21,35 @@ import (
 )
 
 type Handler struct {
-	jwkClient jwts_v1.JwkClient
-	jwtClient jwts_v1.JwtClient
+	jwkClient      jwts_v1.JwkClient
+	jwtClient      jwts_v1.JwtClient
+	jwtsService    JwtsServiceI
+	clientService  ClientServiceI
+	issuer         string
+	publicUrl      string         // base url for discovery, empty - from the request
+	trustedProxies []netip.Prefix // X-Forwarded-* headers are accepted only from these addresses
+	jwksMaxAge     time.Duration  // Cache-Control max-age of the jwk set
 }
 
-func New(jwkClient jwts_v1.JwkClient, jwtClient jwts_v1.JwtClient) *Handler {
+func New(
+	jwkClient jwts_v1.JwkClient,
+	jwtClient jwts_v1.JwtClient,
+	jwtsService JwtsServiceI,
+	clientService ClientServiceI,
+	issuer string,
+	publicUrl string,
+	trustedProxies []netip.Prefix,
+	jwksMaxAge time.Duration,
+) *Handler {
 	return &Handler{
-		jwkClient: jwkClient,
-		jwtClient: jwtClient,
+		jwkClient:      jwkClient,
+		jwtClient:      jwtClient,
+		jwtsService:    jwtsService,
+		clientService:  clientService,
+		issuer:         issuer,
+		publicUrl:      strings.TrimSuffix(publicUrl, "/"),
+		trustedProxies: trustedProxies,
+		jwksMaxAge:     jwksMaxAge,
 	}
 }
 
@@ -31,7 +58,32 @@ func (h *Handler) JwkGetSet(w http.ResponseWriter, r *http.Request) {
 	if checkErr(err, r, w) {
 		return
 	}
-	sendJson(grpcRepObj, w, http.StatusOK)
+
+	body, err := json.Marshal(grpcRepObj)
+	if checkErr(err, r, w) {
+		return
+	}
+	body = append(body, '\n')
+
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+
+	if h.jwksMaxAge > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(h.jwksMaxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
 }
 
 func (h *Handler) JwtCreate(w http.ResponseWriter, r *http.Request) {
@@ -42,8 +94,12 @@ func (h *Handler) JwtCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// numbers are kept as is for the payload
+	decoder := json.NewDecoder(bytes.NewReader(reqBody))
+	decoder.UseNumber()
+
 	reqObj := map[string]any{}
-	if err = json.Unmarshal(reqBody, &reqObj); err != nil {
+	if err = decoder.Decode(&reqObj); err != nil {
 		err = fmt.Errorf("fail to unmarshal request-body %w, body: %s", err, string(reqBody))
 		checkErr(err, r, w)
 		return
@@ -80,6 +136,115 @@ func (h *Handler) JwtCreate(w http.ResponseWriter, r *http.Request) {
 		grpcReqObj.ExpSeconds = int64(v)
 	}
 
+	if av, ok = reqObj["with_refresh"]; ok {
+		if grpcReqObj.WithRefresh, ok = av.(bool); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.ServiceNA.Error(),
+				Desc:      "with_refresh must be bool",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if av, ok = reqObj["refresh_exp_seconds"]; ok {
+		v, err := strconv.ParseFloat(fmt.Sprintf("%v", av), 64)
+		if err != nil {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.ServiceNA.Error(),
+				Desc:      "fail to parse refresh_exp_seconds",
+			}, w, http.StatusBadRequest)
+			return
+		}
+		grpcReqObj.RefreshExpSeconds = int64(v)
+	}
+
+	if av, ok = reqObj["iss"]; ok {
+		if grpcReqObj.Iss, ok = av.(string); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "iss must be string",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if av, ok = reqObj["aud"]; ok {
+		if grpcReqObj.Aud, ok = jsonStrings(av); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "aud must be string or list of strings",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	for k, dst := range map[string]*int64{"exp": &grpcReqObj.Exp, "nbf": &grpcReqObj.Nbf, "iat": &grpcReqObj.Iat} {
+		if av, ok = reqObj[k]; !ok {
+			continue
+		}
+
+		if *dst, ok = jsonUnixTime(av); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      k + " must be unix time in seconds",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if av, ok = reqObj["nbf_seconds"]; ok {
+		if grpcReqObj.NbfSeconds, ok = jsonInt(av); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "nbf_seconds must be integer",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if av, ok = reqObj["jti"]; ok {
+		if grpcReqObj.Jti, ok = av.(string); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "jti must be string",
+			}, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// the JOSE header is passed in the query, any key of the body can be a claim
+	if v := r.URL.Query().Get("header"); v != "" {
+		headerDecoder := json.NewDecoder(strings.NewReader(v))
+		headerDecoder.UseNumber()
+
+		header := map[string]any{}
+		if err = headerDecoder.Decode(&header); err != nil {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "header must be json object",
+			}, w, http.StatusBadRequest)
+			return
+		}
+
+		if !jwtCreateHeader(grpcReqObj, header, w) {
+			return
+		}
+	}
+
+	// request options and registered claims are not custom claims
+	payloadLen := len(reqObj)
+
+	for _, k := range []string{"sub", "exp_seconds", "with_refresh", "refresh_exp_seconds", "iss", "aud", "exp", "nbf", "nbf_seconds", "iat", "jti"} {
+		delete(reqObj, k)
+	}
+
+	if len(reqObj) != payloadLen {
+		grpcReqObj.Payload, err = json.Marshal(reqObj)
+		if checkErr(err, r, w) {
+			return
+		}
+	}
+
 	grpcRepObj, err := h.jwtClient.Create(r.Context(), grpcReqObj)
 	if checkErr(err, r, w) {
 		return
@@ -88,6 +253,125 @@ func (h *Handler) JwtCreate(w http.ResponseWriter, r *http.Request) {
 	sendJson(grpcRepObj, w, http.StatusOK)
 }
 
+// jwtCreateHeader sets header fields of the create request from the "header" query parameter
+func jwtCreateHeader(grpcReqObj *jwts_v1.JwtCreateReq, header map[string]any, w http.ResponseWriter) bool {
+	var v any
+	var ok bool
+
+	for k, dst := range map[string]*string{"typ": &grpcReqObj.Typ, "cty": &grpcReqObj.Cty} {
+		if v, ok = header[k]; !ok {
+			continue
+		}
+
+		if *dst, ok = v.(string); !ok {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "header." + k + " must be string",
+			}, w, http.StatusBadRequest)
+			return false
+		}
+
+		delete(header, k)
+	}
+
+	if v, ok = header["crit"]; ok {
+		_, isList := v.([]any)
+
+		if grpcReqObj.Crit, ok = jsonStrings(v); !ok || !isList {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "header.crit must be list of strings",
+			}, w, http.StatusBadRequest)
+			return false
+		}
+
+		delete(header, "crit")
+	}
+
+	if len(header) > 0 {
+		var err error
+
+		grpcReqObj.Header, err = json.Marshal(header)
+		if err != nil {
+			sendJson(&ErrorRep{
+				ErrorCode: errs.InvalidRequest.Error(),
+				Desc:      "fail to marshal header",
+			}, w, http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
+// jsonStrings parses a string or a list of strings
+func jsonStrings(v any) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case []any:
+		result := make([]string, 0, len(v))
+
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+
+			result = append(result, str)
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+// jsonInt parses an integer json.Number
+func jsonInt(v any) (int64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	result, err := n.Int64()
+	if err != nil {
+		return 0, false
+	}
+
+	return result, true
+}
+
+// jsonUnixTime parses a positive integer json.Number
+func jsonUnixTime(v any) (int64, bool) {
+	result, ok := jsonInt(v)
+
+	return result, ok && result > 0
+}
+
+func (h *Handler) JwtRefresh(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("fail to read request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	reqObj := &jwts_v1.JwtRefreshReq{}
+	if err = json.Unmarshal(reqBody, reqObj); err != nil {
+		err = fmt.Errorf("fail to unmarshal request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	grpcRepObj, err := h.jwtClient.Refresh(r.Context(), reqObj)
+	if checkErr(err, r, w) {
+		return
+	}
+
+	sendJson(grpcRepObj, w, http.StatusOK)
+}
+
 func (h *Handler) JwtValidate(w http.ResponseWriter, r *http.Request) {
 	reqBody, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -108,8 +392,84 @@ func (h *Handler) JwtValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sendJson(&JwtValidateRep{
+	rep := &JwtValidateRep{
 		Valid:  grpcRepObj.Valid,
 		Claims: grpcRepObj.Claims,
-	}, w, http.StatusOK)
+		Detail: grpcRepObj.Detail,
+
+		UnverifiedClaims: grpcRepObj.UnverifiedClaims,
+	}
+
+	if grpcRepObj.Reason != jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_NONE {
+		// JWT_INVALID_REASON_BAD_SIGNATURE -> bad_signature
+		rep.Reason = strings.ToLower(strings.TrimPrefix(grpcRepObj.Reason.String(), "JWT_INVALID_REASON_"))
+	}
+
+	sendJson(rep, w, http.StatusOK)
+}
+
+func (h *Handler) JwtRevoke(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("fail to read request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	reqObj := &jwts_v1.JwtRevokeReq{}
+	if err = json.Unmarshal(reqBody, reqObj); err != nil {
+		err = fmt.Errorf("fail to unmarshal request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	grpcRepObj, err := h.jwtClient.Revoke(r.Context(), reqObj)
+	if checkErr(err, r, w) {
+		return
+	}
+
+	sendJson(grpcRepObj, w, http.StatusOK)
+}
+
+func (h *Handler) JwtRevokeSubject(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("fail to read request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	reqObj := &jwts_v1.JwtRevokeSubjectReq{}
+	if err = json.Unmarshal(reqBody, reqObj); err != nil {
+		err = fmt.Errorf("fail to unmarshal request-body %w", err)
+		checkErr(err, r, w)
+		return
+	}
+
+	grpcRepObj, err := h.jwtClient.RevokeSubject(r.Context(), reqObj)
+	if checkErr(err, r, w) {
+		return
+	}
+
+	sendJson(grpcRepObj, w, http.StatusOK)
+}
+
+func (h *Handler) JwtListSubjectCutoffs(w http.ResponseWriter, r *http.Request) {
+	grpcRepObj, err := h.jwtClient.ListSubjectCutoffs(r.Context(), &emptypb.Empty{})
+	if checkErr(err, r, w) {
+		return
+	}
+
+	sendJson(grpcRepObj, w, http.StatusOK)
+}
+
+func (h *Handler) JwtClearSubjectCutoff(w http.ResponseWriter, r *http.Request) {
+	grpcRepObj, err := h.jwtClient.ClearSubjectCutoff(r.Context(), &jwts_v1.JwtClearSubjectCutoffReq{
+		Sub: r.PathValue("sub"),
+	})
+	if checkErr(err, r, w) {
+		return
+	}
+
+	sendJson(grpcRepObj, w, http.StatusOK)
 }
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rendau/jwts/internal/errs"
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	"github.com/rendau/jwts/pkg/proto/common"
+)
+
+func TestJwtCreate(t *testing.T) {
+	jwtClient := &fakeCreateJwtClient{}
+
+	h := New(nil, jwtClient, nil, clientServiceP.New(nil, 0), "", "", nil, 0)
+
+	createWithHeader := func(body, header string) int {
+		target := "/jwt"
+		if header != "" {
+			target += "?header=" + url.QueryEscape(header)
+		}
+
+		w := httptest.NewRecorder()
+		h.JwtCreate(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+
+		return w.Code
+	}
+
+	create := func(body string) int {
+		return createWithHeader(body, "")
+	}
+
+	code := create(`{"sub": "user-1", "iss": "a", "aud": "api", "exp": 1900000000, "nbf_seconds": -30, "iat": 1700000000, "jti": "id-1", "role": "admin"}`)
+	require.Equal(t, http.StatusOK, code)
+
+	require.Equal(t, "user-1", jwtClient.req.Sub)
+	require.Equal(t, "a", jwtClient.req.Iss)
+	require.Equal(t, []string{"api"}, jwtClient.req.Aud)
+	require.Equal(t, int64(1900000000), jwtClient.req.Exp)
+	require.Equal(t, int64(-30), jwtClient.req.NbfSeconds)
+	require.Equal(t, int64(1700000000), jwtClient.req.Iat)
+	require.Equal(t, "id-1", jwtClient.req.Jti)
+	require.JSONEq(t, `{"role": "admin"}`, string(jwtClient.req.Payload))
+
+	code = createWithHeader(`{"sub": "user-1"}`, `{"typ": "at+jwt", "crit": ["urn:ext"], "urn:ext": 1}`)
+	require.Equal(t, http.StatusOK, code)
+
+	require.Equal(t, "at+jwt", jwtClient.req.Typ)
+	require.Equal(t, []string{"urn:ext"}, jwtClient.req.Crit)
+	require.JSONEq(t, `{"urn:ext": 1}`, string(jwtClient.req.Header))
+	require.JSONEq(t, `{}`, string(jwtClient.req.Payload))
+
+	// "header" of the body is a claim
+	code = create(`{"sub": "user-1", "header": {"typ": "at+jwt"}}`)
+	require.Equal(t, http.StatusOK, code)
+
+	require.Empty(t, jwtClient.req.Typ)
+	require.JSONEq(t, `{"header": {"typ": "at+jwt"}}`, string(jwtClient.req.Payload))
+
+	require.Equal(t, http.StatusBadRequest, createWithHeader(`{}`, `{"crit": "urn:ext"}`))
+	require.Equal(t, http.StatusBadRequest, createWithHeader(`{}`, `["typ"]`))
+	require.Equal(t, http.StatusBadRequest, create(`{"aud": ["api", 1]}`))
+	require.Equal(t, http.StatusBadRequest, create(`{"exp": 1.5}`))
+	require.Equal(t, http.StatusBadRequest, create(`{"nbf": "now"}`))
+}
+
+func TestCheckErrFields(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "bad request").WithDetails(&common.ErrorRep{
+		Code:    errs.PermissionDenied.Error(),
+		Message: "token request violates the mint policy",
+		Fields:  map[string]string{"sub": "not allowed"},
+	})
+	require.NoError(t, err)
+
+	for _, err := range []error{
+		st.Err(),
+		errs.ErrFull{Err: errs.PermissionDenied, Desc: "token request violates the mint policy", Fields: map[string]string{"sub": "not allowed"}},
+	} {
+		w := httptest.NewRecorder()
+		require.True(t, checkErr(err, httptest.NewRequest(http.MethodPost, "/jwt", nil), w))
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		rep := &ErrorRep{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), rep))
+		require.Equal(t, errs.PermissionDenied.Error(), rep.ErrorCode)
+		require.Equal(t, map[string]string{"sub": "not allowed"}, rep.Fields)
+	}
+}
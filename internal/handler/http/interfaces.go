@@ -0,0 +1,16 @@
+package http
+
+import (
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+	jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+type JwtsServiceI interface {
+	GetKeys() []*jwtsModel.Key
+}
+
+type ClientServiceI interface {
+	HasClients() bool
+	Authenticate(id, secret string) *clientModel.Client
+	Grant(c *clientModel.Client, grantType string, scopes, audiences []string) (*clientModel.Grant, error)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rendau/jwts/pkg/proto/jwts_v1"
+)
+
+// Introspect is the OAuth 2.0 token introspection endpoint (RFC 7662)
+func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		sendOAuthError("invalid_request", "fail to parse form", w, http.StatusBadRequest)
+		return
+	}
+
+	if h.authenticateClient(w, r) == nil {
+		return
+	}
+
+	// token_type_hint is optional and ignored, all tokens are jwt
+	token := r.PostForm.Get("token")
+	if token == "" {
+		sendOAuthError("invalid_request", "token is required", w, http.StatusBadRequest)
+		return
+	}
+
+	strict := true
+
+	grpcRepObj, err := h.jwtClient.Validate(r.Context(), &jwts_v1.JwtValidateReq{
+		Token:  token,
+		Strict: &strict,
+	})
+	if err != nil {
+		sendOAuthErr(err, r, w)
+		return
+	}
+
+	rep := &IntrospectRep{}
+
+	if grpcRepObj.Valid {
+		claims := map[string]any{}
+		if err = json.Unmarshal(grpcRepObj.Claims, &claims); err != nil {
+			sendOAuthErr(err, r, w)
+			return
+		}
+
+		rep = introspectRep(claims)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	sendJson(rep, w, http.StatusOK)
+}
+
+func introspectRep(claims map[string]any) *IntrospectRep {
+	result := &IntrospectRep{
+		Active:    true,
+		TokenType: "Bearer",
+		Aud:       claims["aud"],
+	}
+
+	result.Sub, _ = claims["sub"].(string)
+	result.Iss, _ = claims["iss"].(string)
+	result.Jti, _ = claims["jti"].(string)
+	result.Username, _ = claims["preferred_username"].(string)
+
+	result.ClientId, _ = claims["client_id"].(string)
+	if result.ClientId == "" {
+		result.ClientId, _ = claims["azp"].(string)
+	}
+
+	// "scope" is space separated (RFC 9068), some providers use "scp" list
+	switch v := claims["scope"].(type) {
+	case string:
+		result.Scope = v
+	default:
+		if scp, ok := claims["scp"].([]any); ok {
+			scopes := make([]string, 0, len(scp))
+			for _, item := range scp {
+				if s, ok := item.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+			result.Scope = strings.Join(scopes, " ")
+		}
+	}
+
+	for name, dst := range map[string]*int64{"exp": &result.Exp, "iat": &result.Iat, "nbf": &result.Nbf} {
+		if v, ok := claims[name].(float64); ok {
+			*dst = int64(v)
+		}
+	}
+
+	return result
+}
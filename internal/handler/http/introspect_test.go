@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	"github.com/rendau/jwts/pkg/proto/jwts_v1"
+)
+
+type fakeJwtClient struct {
+	jwts_v1.JwtClient
+}
+
+func (c *fakeJwtClient) Validate(ctx context.Context, in *jwts_v1.JwtValidateReq, opts ...grpc.CallOption) (*jwts_v1.JwtValidateRep, error) {
+	if in.Token != "good" || !in.GetStrict() {
+		return &jwts_v1.JwtValidateRep{Reason: jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_EXPIRED}, nil
+	}
+
+	return &jwts_v1.JwtValidateRep{
+		Valid:  true,
+		Claims: []byte(`{"sub":"user-1","exp":1700000000,"scope":"read write","azp":"app","aud":["api"]}`),
+	}, nil
+}
+
+func TestIntrospect(t *testing.T) {
+	secretHash := sha256.Sum256([]byte("secret"))
+
+	clients, err := clientServiceP.ParseClients([]string{"gateway:" + hex.EncodeToString(secretHash[:])})
+	require.NoError(t, err)
+
+	h := New(nil, &fakeJwtClient{}, nil, clientServiceP.New(clients, time.Hour), "", "", nil, 0)
+
+	introspect := func(token, user, password string) (int, map[string]any) {
+		r := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if user != "" {
+			r.SetBasicAuth(user, password)
+		}
+
+		w := httptest.NewRecorder()
+		h.Introspect(w, r)
+
+		rep := map[string]any{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rep))
+
+		return w.Code, rep
+	}
+
+	code, rep := introspect("good", "", "")
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, "invalid_client", rep["error"])
+
+	code, _ = introspect("good", "gateway", "wrong")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, rep = introspect("bad", "gateway", "secret")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]any{"active": false}, rep)
+
+	code, rep = introspect("good", "gateway", "secret")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, true, rep["active"])
+	require.Equal(t, "user-1", rep["sub"])
+	require.Equal(t, "read write", rep["scope"])
+	require.Equal(t, "app", rep["client_id"])
+	require.Equal(t, float64(1700000000), rep["exp"])
+	require.Equal(t, []any{"api"}, rep["aud"])
+}
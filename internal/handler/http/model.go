@@ -5,11 +5,63 @@ import (
 )
 
 type ErrorRep struct {
-	ErrorCode string `json:"error_code"`
-	Desc      string `json:"desc"`
+	ErrorCode string            `json:"error_code"`
+	Desc      string            `json:"desc"`
+	Fields    map[string]string `json:"fields,omitempty"` // invalid fields of the request
 }
 
 type JwtValidateRep struct {
 	Valid  bool            `json:"valid"`
-	Claims json.RawMessage `json:"claims"`
+	Claims json.RawMessage `json:"claims,omitempty"` // omitted for invalid tokens in strict mode
+	Reason string          `json:"reason,omitempty"` // e.g. "expired", "bad_signature"
+	Detail string          `json:"detail,omitempty"`
+
+	UnverifiedClaims json.RawMessage `json:"unverified_claims,omitempty"`
+}
+
+// IntrospectRep - RFC 7662, only "active" is set for inactive tokens
+type IntrospectRep struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       any    `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// OAuthTokenRep - RFC 6749 access token response, RFC 8693 for token exchange
+type OAuthTokenRep struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+// OAuthErrorRep - RFC 6749 error response
+type OAuthErrorRep struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OpenidConfigurationRep - OpenID Connect Discovery 1.0 and RFC 8414 metadata
+type OpenidConfigurationRep struct {
+	Issuer                                    string   `json:"issuer"`
+	JwksUri                                   string   `json:"jwks_uri"`
+	ResponseTypesSupported                    []string `json:"response_types_supported"`
+	SubjectTypesSupported                     []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported          []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpoint                             string   `json:"token_endpoint,omitempty"`
+	TokenEndpointAuthMethodsSupported         []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	GrantTypesSupported                       []string `json:"grant_types_supported,omitempty"`
+	IntrospectionEndpoint                     string   `json:"introspection_endpoint,omitempty"`
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported,omitempty"`
+	RevocationEndpoint                        string   `json:"revocation_endpoint,omitempty"`
+	RevocationEndpointAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported,omitempty"`
 }
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+	"github.com/rendau/jwts/pkg/proto/common"
+	"github.com/rendau/jwts/pkg/proto/jwts_v1"
+)
+
+const (
+	tokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	tokenTypeJwt         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// OAuthToken is the OAuth 2.0 token endpoint, client_credentials and token exchange (RFC 8693) grants are supported
+func (h *Handler) OAuthToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		sendOAuthError("invalid_request", "fail to parse form", w, http.StatusBadRequest)
+		return
+	}
+
+	client := h.authenticateClient(w, r)
+	if client == nil {
+		return
+	}
+
+	r = r.WithContext(clientCallerContext(r, client))
+
+	grantType := r.PostForm.Get("grant_type")
+
+	if grantType != clientModel.GrantTypeClientCredentials && grantType != clientModel.GrantTypeTokenExchange {
+		sendOAuthError("unsupported_grant_type", "grant_type "+grantType+" is not supported", w, http.StatusBadRequest)
+		return
+	}
+
+	// the token exchange grant is checked by the jwt service
+	if grantType == clientModel.GrantTypeTokenExchange {
+		h.oauthTokenExchange(w, r)
+		return
+	}
+
+	grant, err := h.clientService.Grant(client, grantType, strings.Fields(r.PostForm.Get("scope")), r.PostForm["audience"])
+	if err != nil {
+		sendOAuthErr(err, r, w)
+		return
+	}
+
+	payload := map[string]any{
+		"client_id": grant.ClientId,
+	}
+
+	if len(grant.Scopes) > 0 {
+		payload["scope"] = strings.Join(grant.Scopes, " ")
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		sendOAuthErr(err, r, w)
+		return
+	}
+
+	grpcRepObj, err := h.jwtClient.Create(r.Context(), &jwts_v1.JwtCreateReq{
+		Sub:        grant.ClientId,
+		ExpSeconds: int64(grant.TokenTtl.Seconds()),
+		Aud:        grant.Audiences,
+		Payload:    payloadBytes,
+	})
+	if err != nil {
+		sendOAuthErr(err, r, w)
+		return
+	}
+
+	if grpcRepObj.Token == "" {
+		sendOAuthError("server_error", "no signing key", w, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	sendJson(&OAuthTokenRep{
+		AccessToken: grpcRepObj.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(grant.TokenTtl.Seconds()),
+		Scope:       strings.Join(grant.Scopes, " "),
+	}, w, http.StatusOK)
+}
+
+func (h *Handler) oauthTokenExchange(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{"subject_token_type", "actor_token_type", "requested_token_type"} {
+		switch tokenType := r.PostForm.Get(name); tokenType {
+		case "", tokenTypeAccessToken, tokenTypeJwt:
+		default:
+			sendOAuthError("invalid_request", name+" "+tokenType+" is not supported", w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if r.PostForm.Get("subject_token_type") == "" {
+		sendOAuthError("invalid_request", "subject_token_type is required", w, http.StatusBadRequest)
+		return
+	}
+
+	if r.PostForm.Get("actor_token") != "" && r.PostForm.Get("actor_token_type") == "" {
+		sendOAuthError("invalid_request", "actor_token_type is required", w, http.StatusBadRequest)
+		return
+	}
+
+	grpcRepObj, err := h.jwtClient.Exchange(r.Context(), &jwts_v1.JwtExchangeReq{
+		SubjectToken: r.PostForm.Get("subject_token"),
+		ActorToken:   r.PostForm.Get("actor_token"),
+		Audiences:    r.PostForm["audience"],
+		Scopes:       strings.Fields(r.PostForm.Get("scope")),
+	})
+	if err != nil {
+		sendOAuthErr(err, r, w)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	sendJson(&OAuthTokenRep{
+		AccessToken:     grpcRepObj.Token,
+		IssuedTokenType: tokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       grpcRepObj.ExpSeconds,
+		Scope:           strings.Join(grpcRepObj.Scopes, " "),
+	}, w, http.StatusOK)
+}
+
+// OAuthRevoke is the OAuth 2.0 token revocation endpoint (RFC 7009)
+func (h *Handler) OAuthRevoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		sendOAuthError("invalid_request", "fail to parse form", w, http.StatusBadRequest)
+		return
+	}
+
+	client := h.authenticateClient(w, r)
+	if client == nil {
+		return
+	}
+
+	r = r.WithContext(clientCallerContext(r, client))
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		sendOAuthError("invalid_request", "token is required", w, http.StatusBadRequest)
+		return
+	}
+
+	// invalid tokens and tokens issued to other clients are not revoked,
+	// the response is the same to not disclose them (RFC 7009, sections 2.1, 2.2)
+	if h.isTokenOwner(r, client, token) {
+		_, _ = h.jwtClient.Revoke(r.Context(), &jwts_v1.JwtRevokeReq{
+			Token: token,
+		})
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// isTokenOwner reports whether the valid token was issued to the client ("client_id" or "azp" claim)
+func (h *Handler) isTokenOwner(r *http.Request, client *clientModel.Client, token string) bool {
+	grpcRepObj, err := h.jwtClient.Validate(r.Context(), &jwts_v1.JwtValidateReq{
+		Token: token,
+	})
+	if err != nil || !grpcRepObj.Valid {
+		return false
+	}
+
+	claims := map[string]any{}
+	if err = json.Unmarshal(grpcRepObj.Claims, &claims); err != nil {
+		return false
+	}
+
+	return introspectRep(claims).ClientId == client.Id
+}
+
+// authenticateClient checks client_secret_basic or client_secret_post credentials,
+// sends invalid_client error on failure
+func (h *Handler) authenticateClient(w http.ResponseWriter, r *http.Request) *clientModel.Client {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	var client *clientModel.Client
+	if clientId != "" {
+		client = h.clientService.Authenticate(clientId, clientSecret)
+	}
+
+	if client == nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="jwts"`)
+		sendOAuthError("invalid_client", "client authentication failed", w, http.StatusUnauthorized)
+	}
+
+	return client
+}
+
+// clientCallerContext makes the client the caller of the grpc methods requiring authentication
+func clientCallerContext(r *http.Request, client *clientModel.Client) context.Context {
+	return callerModel.NewContext(r.Context(), &callerModel.Caller{
+		Id:     client.Id,
+		Method: callerModel.MethodClient,
+	})
+}
+
+// oauthErrorCodes are passed as is, they are defined by RFC 6749 (5.2) and RFC 8693 (2.2.2)
+var oauthErrorCodes = []string{
+	errs.InvalidRequest.Error(),
+	errs.InvalidGrant.Error(),
+	errs.InvalidScope.Error(),
+	errs.InvalidTarget.Error(),
+	errs.UnauthorizedClient.Error(),
+}
+
+// sendOAuthErr sends the error (local or from grpc) with an OAuth error code,
+// internal codes are mapped, unknown errors are server_error
+func sendOAuthErr(err error, r *http.Request, w http.ResponseWriter) {
+	code, desc := errCodeDesc(err)
+
+	switch {
+	case slices.Contains(oauthErrorCodes, code):
+		sendOAuthError(code, desc, w, http.StatusBadRequest)
+	case code == errs.InvalidToken.Error():
+		sendOAuthError("invalid_request", desc, w, http.StatusBadRequest)
+	case code == errs.PermissionDenied.Error():
+		sendOAuthError("unauthorized_client", desc, w, http.StatusBadRequest)
+	case code == errs.Unauthenticated.Error():
+		sendOAuthError("invalid_client", desc, w, http.StatusUnauthorized)
+	default:
+		if r.Context().Err() == nil {
+			slog.Info(
+				"Http handler error",
+				"error", err.Error(),
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+		}
+
+		sendOAuthError("server_error", "internal error", w, http.StatusInternalServerError)
+	}
+}
+
+// errCodeDesc returns the code and the description of errs.Err, errs.ErrFull or a grpc error with details,
+// empty code for others
+func errCodeDesc(err error) (string, string) {
+	var errFull errs.ErrFull
+	if errors.As(err, &errFull) {
+		return errFull.Err.Error(), errFull.Desc
+	}
+
+	var errBase errs.Err
+	if errors.As(err, &errBase) {
+		return errBase.Error(), err.Error()
+	}
+
+	if st, ok := status.FromError(err); ok && len(st.Details()) > 0 {
+		if errObj, ok := st.Details()[0].(*common.ErrorRep); ok && errObj.Code != errs.ServiceNA.Error() {
+			return errObj.Code, errObj.Message
+		}
+	}
+
+	return "", ""
+}
+
+func sendOAuthError(code, desc string, w http.ResponseWriter, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	sendJson(&OAuthErrorRep{
+		Error:            code,
+		ErrorDescription: desc,
+	}, w, status)
+}
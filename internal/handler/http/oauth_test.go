@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rendau/jwts/internal/errs"
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	"github.com/rendau/jwts/pkg/proto/common"
+	"github.com/rendau/jwts/pkg/proto/jwts_v1"
+)
+
+type fakeCreateJwtClient struct {
+	jwts_v1.JwtClient
+	req *jwts_v1.JwtCreateReq
+}
+
+func (c *fakeCreateJwtClient) Create(ctx context.Context, in *jwts_v1.JwtCreateReq, opts ...grpc.CallOption) (*jwts_v1.JwtCreateRep, error) {
+	c.req = in
+	return &jwts_v1.JwtCreateRep{Token: "token"}, nil
+}
+
+func TestOAuthToken(t *testing.T) {
+	secretHash := sha256.Sum256([]byte("secret"))
+
+	jwtClient := &fakeCreateJwtClient{}
+
+	h := New(nil, jwtClient, nil, clientServiceP.New([]*clientModel.Client{
+		{
+			Id:         "svc",
+			SecretHash: secretHash[:],
+			GrantTypes: []string{clientModel.GrantTypeClientCredentials},
+			Scopes:     []string{"read", "write"},
+			Audiences:  []string{"api"},
+		},
+		{
+			Id:         "gateway",
+			SecretHash: secretHash[:],
+		},
+	}, time.Hour), "", "", nil, 0)
+
+	clientId := "svc"
+
+	token := func(form url.Values) (int, map[string]any) {
+		r := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.SetBasicAuth(clientId, "secret")
+
+		w := httptest.NewRecorder()
+		h.OAuthToken(w, r)
+
+		rep := map[string]any{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rep))
+
+		return w.Code, rep
+	}
+
+	code, rep := token(url.Values{"grant_type": {"password"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "unsupported_grant_type", rep["error"])
+
+	code, rep = token(url.Values{"grant_type": {"client_credentials"}, "scope": {"read admin"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "invalid_scope", rep["error"])
+
+	code, rep = token(url.Values{"grant_type": {"client_credentials"}, "audience": {"other"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "invalid_target", rep["error"])
+
+	code, rep = token(url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "token", rep["access_token"])
+	require.Equal(t, "Bearer", rep["token_type"])
+	require.Equal(t, float64(3600), rep["expires_in"])
+	require.Equal(t, "read", rep["scope"])
+
+	require.Equal(t, "svc", jwtClient.req.Sub)
+	require.Equal(t, int64(3600), jwtClient.req.ExpSeconds)
+	require.Equal(t, []string{"api"}, jwtClient.req.Aud)
+	require.JSONEq(t, `{"client_id":"svc","scope":"read"}`, string(jwtClient.req.Payload))
+
+	// the client without grant types can only introspect
+	clientId = "gateway"
+
+	code, rep = token(url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "unauthorized_client", rep["error"])
+}
+
+type fakeRevokeJwtClient struct {
+	jwts_v1.JwtClient
+	revoked []string
+}
+
+func (c *fakeRevokeJwtClient) Validate(ctx context.Context, in *jwts_v1.JwtValidateReq, opts ...grpc.CallOption) (*jwts_v1.JwtValidateRep, error) {
+	claims := map[string]string{
+		"own":   `{"sub":"svc","client_id":"svc"}`,
+		"azp":   `{"sub":"user-1","azp":"svc"}`,
+		"other": `{"sub":"other","client_id":"other"}`,
+		"user":  `{"sub":"user-1"}`,
+	}[in.Token]
+	if claims == "" {
+		return &jwts_v1.JwtValidateRep{Reason: jwts_v1.JwtInvalidReason_JWT_INVALID_REASON_EXPIRED}, nil
+	}
+
+	return &jwts_v1.JwtValidateRep{Valid: true, Claims: []byte(claims)}, nil
+}
+
+func (c *fakeRevokeJwtClient) Revoke(ctx context.Context, in *jwts_v1.JwtRevokeReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	c.revoked = append(c.revoked, in.Token)
+	return &emptypb.Empty{}, nil
+}
+
+func TestOAuthRevoke(t *testing.T) {
+	secretHash := sha256.Sum256([]byte("secret"))
+
+	jwtClient := &fakeRevokeJwtClient{}
+
+	h := New(nil, jwtClient, nil, clientServiceP.New([]*clientModel.Client{{
+		Id:         "svc",
+		SecretHash: secretHash[:],
+	}}, time.Hour), "", "", nil, 0)
+
+	for _, token := range []string{"own", "azp", "other", "user", "bad"} {
+		r := httptest.NewRequest(http.MethodPost, "/revoke", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.SetBasicAuth("svc", "secret")
+
+		w := httptest.NewRecorder()
+		h.OAuthRevoke(w, r)
+		require.Equal(t, http.StatusOK, w.Code, token)
+	}
+
+	// tokens of other clients are not revoked
+	require.Equal(t, []string{"own", "azp"}, jwtClient.revoked)
+}
+
+func TestSendOAuthErr(t *testing.T) {
+	st, err := status.New(codes.PermissionDenied, "denied").WithDetails(&common.ErrorRep{
+		Code:    errs.PermissionDenied.Error(),
+		Message: "token request violates the mint policy",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		err    error
+		code   string
+		status int
+	}{
+		{errs.ErrFull{Err: errs.InvalidScope, Desc: "scope"}, "invalid_scope", http.StatusBadRequest},
+		{errs.InvalidToken, "invalid_request", http.StatusBadRequest},
+		{st.Err(), "unauthorized_client", http.StatusBadRequest},
+		{errs.ErrFull{Err: errs.Unauthenticated}, "invalid_client", http.StatusUnauthorized},
+		{errs.ServiceNA, "server_error", http.StatusInternalServerError},
+		{errors.New("no verification keys"), "server_error", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		sendOAuthErr(tt.err, httptest.NewRequest(http.MethodPost, "/oauth/token", nil), w)
+
+		rep := &OAuthErrorRep{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), rep))
+		require.Equal(t, tt.code, rep.Error, tt.err.Error())
+		require.Equal(t, tt.status, w.Code, tt.err.Error())
+	}
+}
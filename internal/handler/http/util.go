@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"google.golang.org/grpc/status"
 
@@ -27,7 +28,8 @@ func checkErr(err error, r *http.Request, w http.ResponseWriter) bool {
 				sendJson(&ErrorRep{
 					ErrorCode: errObj.Code,
 					Desc:      errObj.Message,
-				}, w, http.StatusBadRequest)
+					Fields:    errObj.Fields,
+				}, w, errStatus(errObj.Code))
 				return true
 			} else {
 				sendJson(&ErrorRep{
@@ -51,7 +53,7 @@ func checkErr(err error, r *http.Request, w http.ResponseWriter) bool {
 		sendJson(&ErrorRep{
 			ErrorCode: errBase.Error(),
 			Desc:      err.Error(),
-		}, w, http.StatusBadRequest)
+		}, w, errStatus(errBase.Error()))
 		return true
 	}
 
@@ -61,7 +63,8 @@ func checkErr(err error, r *http.Request, w http.ResponseWriter) bool {
 		sendJson(&ErrorRep{
 			ErrorCode: errFull.Err.Error(),
 			Desc:      errFull.Desc,
-		}, w, http.StatusBadRequest)
+			Fields:    errFull.Fields,
+		}, w, errStatus(errFull.Err.Error()))
 		return true
 	}
 
@@ -84,8 +87,32 @@ func checkErr(err error, r *http.Request, w http.ResponseWriter) bool {
 	return true
 }
 
+// errStatus maps caller authentication errors to 401 and 403, others are bad requests
+func errStatus(errCode string) int {
+	switch errCode {
+	case errs.Unauthenticated.Error():
+		return http.StatusUnauthorized
+	case errs.PermissionDenied.Error():
+		return http.StatusForbidden
+	}
+
+	return http.StatusBadRequest
+}
+
 func sendJson(obj any, w http.ResponseWriter, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(obj)
 }
+
+// etagMatch checks If-None-Match header value (list of etags or "*"), weak comparison
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+
+	return false
+}
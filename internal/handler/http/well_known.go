@@ -0,0 +1,139 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/rendau/jwts/internal/errs"
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+)
+
+// OpenidConfiguration serves OpenID Connect discovery and RFC 8414 authorization server metadata,
+// only with a configured issuer: "issuer" must equal "iss" of the minted tokens
+func (h *Handler) OpenidConfiguration(w http.ResponseWriter, r *http.Request) {
+	if h.issuer == "" {
+		sendJson(&ErrorRep{
+			ErrorCode: errs.ServiceNA.Error(),
+			Desc:      "issuer is not configured",
+		}, w, http.StatusNotFound)
+		return
+	}
+
+	// tokens are signed only with local keys, external keys are for validation
+	algs := make([]string, 0)
+	for _, key := range h.jwtsService.GetKeys() {
+		if key.Alg != "" && !slices.Contains(algs, key.Alg) {
+			algs = append(algs, key.Alg)
+		}
+	}
+
+	baseUrl := h.baseUrl(r)
+
+	rep := &OpenidConfigurationRep{
+		Issuer:  h.issuer,
+		JwksUri: baseUrl + "/.well-known/jwks.json",
+		// there is no authorization endpoint
+		ResponseTypesSupported:           []string{},
+		SubjectTypesSupported:            []string{"public"},
+		IdTokenSigningAlgValuesSupported: algs,
+	}
+
+	if h.clientService.HasClients() {
+		authMethods := []string{"client_secret_basic", "client_secret_post"}
+
+		rep.TokenEndpoint = baseUrl + "/oauth/token"
+		rep.TokenEndpointAuthMethodsSupported = authMethods
+		rep.GrantTypesSupported = []string{clientModel.GrantTypeClientCredentials, clientModel.GrantTypeTokenExchange}
+		rep.IntrospectionEndpoint = baseUrl + "/introspect"
+		rep.IntrospectionEndpointAuthMethodsSupported = authMethods
+		rep.RevocationEndpoint = baseUrl + "/revoke"
+		rep.RevocationEndpointAuthMethodsSupported = authMethods
+	}
+
+	// urls built from the request must not be stored by shared caches
+	if h.publicUrl != "" {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+		w.Header().Set("Vary", "Host, X-Forwarded-Host, X-Forwarded-Proto")
+	}
+
+	sendJson(rep, w, http.StatusOK)
+}
+
+// baseUrl returns PUBLIC_URL, otherwise builds the url from the request,
+// X-Forwarded-* headers are used only from trusted proxies
+func (h *Handler) baseUrl(r *http.Request) string {
+	if h.publicUrl != "" {
+		return h.publicUrl
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	host := r.Host
+
+	if h.isTrustedProxy(r) {
+		if v := r.Header.Get("X-Forwarded-Proto"); v == "http" || v == "https" {
+			scheme = v
+		}
+		if v := r.Header.Get("X-Forwarded-Host"); v != "" {
+			host = v
+		}
+	}
+
+	return scheme + "://" + host
+}
+
+func (h *Handler) isTrustedProxy(r *http.Request) bool {
+	if len(h.trustedProxies) == 0 {
+		return false
+	}
+
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	return slices.ContainsFunc(h.trustedProxies, func(p netip.Prefix) bool {
+		return p.Contains(addr)
+	})
+}
+
+// ParseTrustedProxies parses addresses and CIDR ranges of TRUSTED_PROXIES
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	result := make([]netip.Prefix, 0, len(values))
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+			}
+
+			result = append(result, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+		}
+
+		result = append(result, prefix.Masked())
+	}
+
+	return result, nil
+}
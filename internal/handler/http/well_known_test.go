@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
+	"github.com/rendau/jwts/pkg/proto/jwts_v1"
+)
+
+type fakeJwkClient struct{}
+
+func (c *fakeJwkClient) Get(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*jwts_v1.JwkSet, error) {
+	return &jwts_v1.JwkSet{Keys: []*jwts_v1.JwkMain{{Kid: "a", Alg: "RS256"}, {Kid: "b", Alg: "ES256"}, {Kid: "c", Alg: "PS256"}}}, nil
+}
+
+type fakeJwtsService struct{}
+
+func (s *fakeJwtsService) GetKeys() []*jwtsModel.Key {
+	return []*jwtsModel.Key{{Kid: "a", Alg: "RS256"}, {Kid: "b", Alg: "ES256"}, {Kid: "c", Alg: "RS256"}}
+}
+
+func TestOpenidConfiguration(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	get := func(h *Handler, remoteAddr string) (*OpenidConfigurationRep, http.Header) {
+		r := httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil)
+		r.RemoteAddr = remoteAddr
+		r.Host = "jwts:80"
+		r.Header.Set("X-Forwarded-Proto", "https")
+		r.Header.Set("X-Forwarded-Host", "auth.example.com")
+
+		w := httptest.NewRecorder()
+		h.OpenidConfiguration(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		rep := &OpenidConfigurationRep{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), rep))
+
+		return rep, w.Header()
+	}
+
+	h := New(&fakeJwkClient{}, nil, &fakeJwtsService{}, clientServiceP.New(nil, 0), "https://issuer.example.com", "", trustedProxies, 0)
+
+	rep, header := get(h, "192.0.2.1:1234")
+	require.Equal(t, "https://issuer.example.com", rep.Issuer)
+	require.Equal(t, "https://auth.example.com/.well-known/jwks.json", rep.JwksUri)
+	require.Equal(t, []string{"RS256", "ES256"}, rep.IdTokenSigningAlgValuesSupported)
+	require.NotNil(t, rep.ResponseTypesSupported)
+	require.Empty(t, rep.IntrospectionEndpoint)
+	require.Equal(t, "private, max-age=3600", header.Get("Cache-Control"))
+
+	// forwarded headers of untrusted clients are ignored
+	rep, _ = get(h, "203.0.113.5:1234")
+	require.Equal(t, "http://jwts:80/.well-known/jwks.json", rep.JwksUri)
+
+	// configured issuer and public url
+	h = New(&fakeJwkClient{}, nil, &fakeJwtsService{}, clientServiceP.New(nil, 0), "https://issuer.example.com", "https://jwts.example.com/", nil, 0)
+
+	rep, header = get(h, "203.0.113.5:1234")
+	require.Equal(t, "https://issuer.example.com", rep.Issuer)
+	require.Equal(t, "https://jwts.example.com/.well-known/jwks.json", rep.JwksUri)
+	require.Equal(t, "public, max-age=3600", header.Get("Cache-Control"))
+
+	// without issuer tokens have no "iss" to match
+	w := httptest.NewRecorder()
+	New(&fakeJwkClient{}, nil, &fakeJwtsService{}, clientServiceP.New(nil, 0), "", "", nil, 0).
+		OpenidConfiguration(w, httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	require.Error(t, err)
+}
+
+func TestJwkGetSetCaching(t *testing.T) {
+	h := New(&fakeJwkClient{}, nil, nil, clientServiceP.New(nil, 0), "", "", nil, 5*time.Minute)
+
+	w := httptest.NewRecorder()
+	h.JwkGetSet(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	r := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	r.Header.Set("If-None-Match", `"other", W/`+etag)
+
+	w = httptest.NewRecorder()
+	h.JwkGetSet(w, r)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.Bytes())
+	require.Equal(t, etag, w.Header().Get("ETag"))
+}
@@ -0,0 +1,32 @@
+package model
+
+import "context"
+
+const (
+	MethodApiKey      = "api_key"
+	MethodCertificate = "mtls"
+	MethodClient      = "oauth_client" // authenticated by the http layer with client credentials
+)
+
+// Caller is an authenticated party allowed to mint and revoke tokens
+type Caller struct {
+	Id     string
+	Method string
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, c *Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns nil if the context has no caller
+func FromContext(ctx context.Context) *Caller {
+	c, _ := ctx.Value(contextKey{}).(*Caller)
+	return c
+}
+
+type ApiKey struct {
+	CallerId string
+	KeyHash  []byte // sha256 of the key
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/rendau/jwts/internal/service/caller/model"
+)
+
+// Service authenticates callers of the token minting api by api keys and verified client certificates
+type Service struct {
+	keys        []*model.ApiKey
+	certEnabled bool
+}
+
+func New(keys []*model.ApiKey, certEnabled bool) *Service {
+	return &Service{
+		keys:        keys,
+		certEnabled: certEnabled,
+	}
+}
+
+// ParseApiKeys parses "<caller_id>:<sha256 hex of key>" items
+func ParseApiKeys(items []string) ([]*model.ApiKey, error) {
+	result := make([]*model.ApiKey, 0, len(items))
+
+	for _, item := range items {
+		callerId, hashHex, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || callerId == "" {
+			return nil, fmt.Errorf("api key %q: must be <caller_id>:<sha256 hex of key>", item)
+		}
+
+		keyHash, err := hex.DecodeString(hashHex)
+		if err != nil || len(keyHash) != sha256.Size {
+			return nil, fmt.Errorf("api key of %q: must be sha256 hex", callerId)
+		}
+
+		result = append(result, &model.ApiKey{
+			CallerId: callerId,
+			KeyHash:  keyHash,
+		})
+	}
+
+	return result, nil
+}
+
+// Enabled reports whether callers must authenticate
+func (s *Service) Enabled() bool {
+	return len(s.keys) > 0 || s.certEnabled
+}
+
+// AuthenticateKey returns the caller of the api key, nil if unknown
+func (s *Service) AuthenticateKey(key string) *model.Caller {
+	if key == "" {
+		return nil
+	}
+
+	keyHash := sha256.Sum256([]byte(key))
+
+	var result *model.Caller
+
+	// all keys are compared, so the time does not depend on the match position
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(keyHash[:], k.KeyHash) == 1 && result == nil {
+			result = &model.Caller{
+				Id:     k.CallerId,
+				Method: model.MethodApiKey,
+			}
+		}
+	}
+
+	return result
+}
+
+// AuthenticateCert returns the caller of the client certificate verified by tls against the client CA.
+// Caller id is the subject common name, or the first DNS name
+func (s *Service) AuthenticateCert(verifiedChains [][]*x509.Certificate) *model.Caller {
+	if !s.certEnabled || len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := verifiedChains[0][0]
+
+	id := cert.Subject.CommonName
+	if id == "" && len(cert.DNSNames) > 0 {
+		id = cert.DNSNames[0]
+	}
+
+	if id == "" {
+		return nil
+	}
+
+	return &model.Caller{
+		Id:     id,
+		Method: model.MethodCertificate,
+	}
+}
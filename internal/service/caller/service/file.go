@@ -0,0 +1,39 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rendau/jwts/internal/service/caller/model"
+)
+
+type apiKeysFile struct {
+	Keys []apiKeysFileItem `json:"keys"`
+}
+
+type apiKeysFileItem struct {
+	CallerId  string `json:"caller_id"`
+	KeySha256 string `json:"key_sha256"`
+}
+
+// LoadApiKeys reads api keys file
+func LoadApiKeys(path string) ([]*model.ApiKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf apiKeysFile
+	if err = json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("fail to parse api keys file: %w", err)
+	}
+
+	items := make([]string, 0, len(kf.Keys))
+
+	for _, item := range kf.Keys {
+		items = append(items, item.CallerId+":"+item.KeySha256)
+	}
+
+	return ParseApiKeys(items)
+}
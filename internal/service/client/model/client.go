@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+type Client struct {
+	Id         string
+	SecretHash []byte   // sha256 of the secret
+	GrantTypes []string // allowed grant types, empty - no tokens (introspection only)
+	Scopes     []string // allowed to request
+	Audiences  []string // allowed to request
+	TokenTtl   time.Duration
+}
+
+// Grant is a checked token request
+type Grant struct {
+	GrantType string
+	ClientId  string
+	Scopes    []string
+	Audiences []string
+	TokenTtl  time.Duration
+}
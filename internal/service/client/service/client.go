@@ -0,0 +1,124 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rendau/jwts/internal/errs"
+	"github.com/rendau/jwts/internal/service/client/model"
+)
+
+// Service authenticates clients of the service (introspection, token endpoint, etc.)
+type Service struct {
+	clients         map[string]*model.Client
+	defaultTokenTtl time.Duration
+}
+
+func New(clients []*model.Client, defaultTokenTtl time.Duration) *Service {
+	s := &Service{
+		clients:         make(map[string]*model.Client, len(clients)),
+		defaultTokenTtl: defaultTokenTtl,
+	}
+
+	for _, c := range clients {
+		s.clients[c.Id] = c
+	}
+
+	return s
+}
+
+// ParseClients parses "<client_id>:<sha256 hex of secret>" items
+func ParseClients(items []string) ([]*model.Client, error) {
+	result := make([]*model.Client, 0, len(items))
+
+	for _, item := range items {
+		id, secretHashHex, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("client %q: must be <client_id>:<sha256 hex of secret>", item)
+		}
+
+		secretHash, err := hex.DecodeString(secretHashHex)
+		if err != nil || len(secretHash) != sha256.Size {
+			return nil, fmt.Errorf("client %q: secret must be sha256 hex", id)
+		}
+
+		result = append(result, &model.Client{
+			Id:         id,
+			SecretHash: secretHash,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Service) HasClients() bool {
+	return len(s.clients) > 0
+}
+
+// Get returns the client by id, nil if not found
+func (s *Service) Get(id string) *model.Client {
+	return s.clients[id]
+}
+
+// Authenticate returns the client if the secret matches, nil otherwise
+func (s *Service) Authenticate(id, secret string) *model.Client {
+	secretHash := sha256.Sum256([]byte(secret))
+
+	c := s.clients[id]
+	if c == nil {
+		return nil
+	}
+
+	if subtle.ConstantTimeCompare(secretHash[:], c.SecretHash) != 1 {
+		return nil
+	}
+
+	return c
+}
+
+// Grant checks the grant type, requested scopes and audiences of the token request.
+// Empty request means all allowed ones
+func (s *Service) Grant(c *model.Client, grantType string, scopes, audiences []string) (*model.Grant, error) {
+	if !slices.Contains(c.GrantTypes, grantType) {
+		return nil, errs.ErrFull{Err: errs.UnauthorizedClient, Desc: fmt.Sprintf("grant_type %s is not allowed for the client", grantType)}
+	}
+
+	result := &model.Grant{
+		GrantType: grantType,
+		ClientId:  c.Id,
+		Scopes:    c.Scopes,
+		Audiences: c.Audiences,
+		TokenTtl:  c.TokenTtl,
+	}
+
+	if result.TokenTtl <= 0 {
+		result.TokenTtl = s.defaultTokenTtl
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			if !slices.Contains(c.Scopes, scope) {
+				return nil, errs.ErrFull{Err: errs.InvalidScope, Desc: fmt.Sprintf("scope %q is not allowed", scope)}
+			}
+		}
+
+		result.Scopes = scopes
+	}
+
+	if len(audiences) > 0 {
+		for _, aud := range audiences {
+			if !slices.Contains(c.Audiences, aud) {
+				return nil, errs.ErrFull{Err: errs.InvalidTarget, Desc: fmt.Sprintf("audience %q is not allowed", aud)}
+			}
+		}
+
+		result.Audiences = audiences
+	}
+
+	return result, nil
+}
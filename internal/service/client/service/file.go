@@ -0,0 +1,75 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/client/model"
+)
+
+type clientsFile struct {
+	Clients []clientsFileItem `json:"clients"`
+}
+
+type clientsFileItem struct {
+	Id           string   `json:"id"`
+	SecretSha256 string   `json:"secret_sha256"`
+	GrantTypes   []string `json:"grant_types"`
+	Scopes       []string `json:"scopes"`
+	Audiences    []string `json:"audiences"`
+	TokenTtl     string   `json:"token_ttl"` // duration, e.g. "15m"
+}
+
+// LoadClients reads clients registry file
+func LoadClients(path string) ([]*model.Client, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cf clientsFile
+	if err = json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("fail to parse clients file: %w", err)
+	}
+
+	result := make([]*model.Client, 0, len(cf.Clients))
+
+	for _, item := range cf.Clients {
+		if item.Id == "" {
+			return nil, fmt.Errorf("clients file: client id is required")
+		}
+
+		for _, grantType := range item.GrantTypes {
+			if grantType != model.GrantTypeClientCredentials && grantType != model.GrantTypeTokenExchange {
+				return nil, fmt.Errorf("client %q: unsupported grant type %q", item.Id, grantType)
+			}
+		}
+
+		c := &model.Client{
+			Id:         item.Id,
+			GrantTypes: item.GrantTypes,
+			Scopes:     item.Scopes,
+			Audiences:  item.Audiences,
+		}
+
+		c.SecretHash, err = hex.DecodeString(item.SecretSha256)
+		if err != nil || len(c.SecretHash) != sha256.Size {
+			return nil, fmt.Errorf("client %q: secret_sha256 must be sha256 hex", item.Id)
+		}
+
+		if item.TokenTtl != "" {
+			c.TokenTtl, err = time.ParseDuration(item.TokenTtl)
+			if err != nil {
+				return nil, fmt.Errorf("client %q: token_ttl: %w", item.Id, err)
+			}
+		}
+
+		result = append(result, c)
+	}
+
+	return result, nil
+}
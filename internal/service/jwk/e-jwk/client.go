@@ -0,0 +1,99 @@
+package e_jwk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwk/model"
+)
+
+// Client fetches jwks over http, the last fetched set is kept to serve conditional requests
+type Client struct {
+	http *http.Client
+
+	mu     sync.Mutex
+	url    string
+	etag   string
+	result *model.JwkSet
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		http: httpClient,
+	}
+}
+
+func (c *Client) Fetch(ctx context.Context, url string) (*model.JwkSet, time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetch jwks - build request: %w", err)
+	}
+
+	if c.url == url && c.etag != "" && c.result != nil {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetch jwks - do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	maxAge := ParseMaxAge(resp.Header.Get("Cache-Control"))
+
+	if resp.StatusCode == http.StatusNotModified && c.url == url && c.result != nil {
+		return c.result, maxAge, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, 0, fmt.Errorf("fetch jwks - bad status %s: %s", resp.Status, string(b))
+	}
+
+	var result *model.JwkSet
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("fetch jwks - decode jwks: %w", err)
+	}
+
+	if result == nil {
+		return nil, 0, fmt.Errorf("fetch jwks - empty jwks")
+	}
+
+	c.url = url
+	c.etag = resp.Header.Get("ETag")
+	c.result = result
+
+	return result, maxAge, nil
+}
+
+// ParseMaxAge returns max-age directive of Cache-Control header, zero if absent or caching is disabled
+func ParseMaxAge(cacheControl string) time.Duration {
+	var result time.Duration
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		if directive == "no-cache" || directive == "no-store" {
+			return 0
+		}
+
+		if v, ok := strings.CutPrefix(directive, "max-age="); ok {
+			seconds, err := strconv.Atoi(strings.Trim(v, `"`))
+			if err == nil && seconds > 0 {
+				result = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,16 @@
+package e_jwk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaxAge(t *testing.T) {
+	require.Equal(t, time.Duration(0), ParseMaxAge(""))
+	require.Equal(t, 60*time.Second, ParseMaxAge("max-age=60"))
+	require.Equal(t, 60*time.Second, ParseMaxAge("public, max-age=60"))
+	require.Equal(t, time.Duration(0), ParseMaxAge("max-age=60, no-cache"))
+	require.Equal(t, time.Duration(0), ParseMaxAge("max-age=abc"))
+}
@@ -2,10 +2,16 @@ package e_jwk
 
 import (
 	"context"
+	"time"
 
 	"github.com/rendau/jwts/internal/service/jwk/model"
 )
 
 type EJwkServiceI interface {
-	FetchJwks(ctx context.Context) (*model.JwkSet, error)
+	// Name identifies the upstream in logs and status
+	Name() string
+	// Issuer returns expected "iss" claim of tokens signed by the keys, empty - any
+	Issuer() string
+	// FetchJwks returns the key set and its max-age hint, zero if unknown
+	FetchJwks(ctx context.Context) (*model.JwkSet, time.Duration, error)
 }
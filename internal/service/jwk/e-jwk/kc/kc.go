@@ -2,63 +2,63 @@ package kc
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
 	"github.com/rendau/jwts/internal/service/jwk/model"
 )
 
 type Service struct {
-	http      *http.Client
+	client    *e_jwk.Client
 	url       string
 	realmName string
+	issuer    string
 }
 
-func New(url, realmName string) *Service {
+// New - issuer of the realm tokens, default <url>/realms/<realmName>
+func New(url, realmName, issuer string) *Service {
 	if url == "" || realmName == "" {
 		return nil
 	}
 
+	url = strings.TrimSuffix(url, "/")
+
+	if issuer == "" {
+		issuer = fmt.Sprintf("%s/realms/%s", url, realmName)
+	}
+
 	return &Service{
-		http: &http.Client{
+		client: e_jwk.NewClient(&http.Client{
 			Timeout: 60 * time.Second,
 			Transport: &http.Transport{
-				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
 				MaxIdleConnsPerHost: 100,
 			},
-		},
+		}),
 		url:       url,
 		realmName: realmName,
+		issuer:    issuer,
 	}
 }
 
-func (s *Service) FetchJwks(ctx context.Context) (*model.JwkSet, error) {
-	url := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", s.url, s.realmName)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("kc.service - fetch jwks - build request: %w", err)
-	}
+func (s *Service) Name() string {
+	return fmt.Sprintf("%s/realms/%s", s.url, s.realmName)
+}
 
-	resp, err := s.http.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("kc.service - fetch jwks - do request: %w", err)
-	}
-	defer resp.Body.Close()
+// Issuer - KC_ISSUER if KC_URL is an internal address, which differs from the token issuer
+func (s *Service) Issuer() string {
+	return s.issuer
+}
 
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
-		return nil, fmt.Errorf("kc.service - fetch jwks - bad status %s: %s", resp.Status, string(b))
-	}
+func (s *Service) FetchJwks(ctx context.Context) (*model.JwkSet, time.Duration, error) {
+	url := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", s.url, s.realmName)
 
-	var result *model.JwkSet
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("kc.service - fetch jwks - decode jwks: %w", err)
+	result, maxAge, err := s.client.Fetch(ctx, url)
+	if err != nil {
+		return nil, 0, fmt.Errorf("kc.service - %w", err)
 	}
 
-	return result, nil
+	return result, maxAge, nil
 }
@@ -0,0 +1,47 @@
+package kc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchJwks(t *testing.T) {
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/realms/test/protocol/openid-connect/certs", r.URL.Path)
+
+		w.Header().Set("Cache-Control", "public, max-age=120")
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"keys":[{"kid":"k1","kty":"EC","crv":"P-256","x":"xx","y":"yy"}]}`))
+	}))
+	defer srv.Close()
+
+	s := New(srv.URL, "test", "")
+	require.Equal(t, srv.URL+"/realms/test", s.Issuer())
+
+	for range 2 {
+		jwks, maxAge, err := s.FetchJwks(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Minute, maxAge)
+		require.Len(t, jwks.Keys, 1)
+		require.Equal(t, "k1", jwks.Keys[0].Kid)
+		require.Equal(t, "P-256", jwks.Keys[0].Crv)
+	}
+
+	require.Equal(t, 2, requests)
+
+	require.Equal(t, "https://auth.example.com/realms/test", New(srv.URL, "test", "https://auth.example.com/realms/test").Issuer())
+}
@@ -0,0 +1,128 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
+	"github.com/rendau/jwts/internal/service/jwk/model"
+)
+
+// Service fetches jwks of any OIDC provider.
+// Jwks url is taken as is or resolved from the issuer discovery document
+type Service struct {
+	httpClient *http.Client
+	client     *e_jwk.Client
+	issuer     string
+
+	mu      sync.Mutex
+	jwksUrl string
+}
+
+func New(issuer, jwksUrl string) *Service {
+	httpClient := &http.Client{
+		Timeout: 60 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: 100,
+		},
+	}
+
+	return &Service{
+		httpClient: httpClient,
+		client:     e_jwk.NewClient(httpClient),
+		issuer:     issuer,
+		jwksUrl:    jwksUrl,
+	}
+}
+
+// ParseUpstream parses upstream definition: "<issuer>" or "<issuer>|<jwks_url>"
+func ParseUpstream(v string) (*Service, error) {
+	issuer, jwksUrl, _ := strings.Cut(strings.TrimSpace(v), "|")
+
+	issuer = strings.TrimSpace(issuer)
+	jwksUrl = strings.TrimSpace(jwksUrl)
+
+	if issuer == "" {
+		return nil, fmt.Errorf("upstream %q: issuer is required", v)
+	}
+
+	return New(issuer, jwksUrl), nil
+}
+
+func (s *Service) Name() string {
+	return s.issuer
+}
+
+func (s *Service) Issuer() string {
+	return s.issuer
+}
+
+func (s *Service) FetchJwks(ctx context.Context) (*model.JwkSet, time.Duration, error) {
+	url, err := s.getJwksUrl(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("oidc.service %s - %w", s.issuer, err)
+	}
+
+	result, maxAge, err := s.client.Fetch(ctx, url)
+	if err != nil {
+		return nil, 0, fmt.Errorf("oidc.service %s - %w", s.issuer, err)
+	}
+
+	return result, maxAge, nil
+}
+
+// getJwksUrl returns configured jwks url or discovers it once
+func (s *Service) getJwksUrl(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jwksUrl != "" {
+		return s.jwksUrl, nil
+	}
+
+	url := strings.TrimSuffix(s.issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("discovery - build request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("discovery - do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return "", fmt.Errorf("discovery - bad status %s: %s", resp.Status, string(b))
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JwksUri string `json:"jwks_uri"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("discovery - decode: %w", err)
+	}
+
+	// OpenID Connect Discovery 1.0, section 4.3
+	if doc.Issuer != s.issuer {
+		return "", fmt.Errorf("discovery - issuer mismatch: %q", doc.Issuer)
+	}
+
+	if doc.JwksUri == "" {
+		return "", fmt.Errorf("discovery - no jwks_uri")
+	}
+
+	s.jwksUrl = doc.JwksUri
+
+	return s.jwksUrl, nil
+}
@@ -0,0 +1,61 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchJwksDiscovery(t *testing.T) {
+	discoveries := 0
+
+	var srv *httptest.Server
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant/.well-known/openid-configuration":
+			discoveries++
+			_, _ = w.Write([]byte(`{"issuer":"` + srv.URL + `/tenant/","jwks_uri":"` + srv.URL + `/keys"}`))
+		case "/keys":
+			_, _ = w.Write([]byte(`{"keys":[{"kid":"k1","kty":"RSA","n":"nn","e":"AQAB"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := New(srv.URL+"/tenant/", "")
+
+	for range 2 {
+		jwks, _, err := s.FetchJwks(context.Background())
+		require.NoError(t, err)
+		require.Len(t, jwks.Keys, 1)
+		require.Equal(t, "k1", jwks.Keys[0].Kid)
+	}
+
+	require.Equal(t, 1, discoveries)
+
+	// issuer of discovery document must match
+	s = New(srv.URL+"/tenant", "")
+
+	_, _, err := s.FetchJwks(context.Background())
+	require.Error(t, err)
+}
+
+func TestParseUpstream(t *testing.T) {
+	s, err := ParseUpstream(" https://idp.example.com/ | https://idp.example.com/keys ")
+	require.NoError(t, err)
+	require.Equal(t, "https://idp.example.com/", s.Issuer())
+	require.Equal(t, "https://idp.example.com/keys", s.jwksUrl)
+
+	s, err = ParseUpstream("https://login.example.com/tenant/v2.0")
+	require.NoError(t, err)
+	require.Equal(t, "https://login.example.com/tenant/v2.0", s.Issuer())
+	require.Empty(t, s.jwksUrl)
+
+	_, err = ParseUpstream("|https://idp.example.com/keys")
+	require.Error(t, err)
+}
@@ -1,5 +1,10 @@
 package model
 
+import (
+	"crypto"
+	"time"
+)
+
 type JwkMain struct {
 	Kty string
 	E   string
@@ -7,8 +12,28 @@ type JwkMain struct {
 	Alg string
 	N   string
 	Use string
+	Crv string
+	X   string
+	Y   string
 }
 
 type JwkSet struct {
 	Keys []*JwkMain
 }
+
+// VerificationKey is a parsed public key of the jwk
+type VerificationKey struct {
+	Kid       string
+	Alg       string // empty - any algorithm of the key type
+	Issuer    string // expected "iss" claim, empty - any
+	PublicKey crypto.PublicKey
+}
+
+// UpstreamStatus is a health state of the external jwk set
+type UpstreamStatus struct {
+	Name          string
+	Issuer        string
+	Healthy       bool // last fetch succeeded
+	LastSuccessAt time.Time
+	LastError     string
+}
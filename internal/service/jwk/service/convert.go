@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/rendau/jwts/internal/service/jwk/model"
+)
+
+// publicKeyJwk builds key-type specific members of the jwk
+func publicKeyJwk(publicKey crypto.PublicKey) (*model.JwkMain, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		eBytes := make([]byte, 4, 4)
+		binary.LittleEndian.PutUint32(eBytes, uint32(key.E))
+
+		return &model.JwkMain{
+			Kty: "RSA",
+			E:   base64.RawURLEncoding.EncodeToString(eBytes[:3]),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		ecdhKey, err := key.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("ecdsa.PublicKey.ECDH: %w", err)
+		}
+
+		// uncompressed point: 0x04 || X || Y
+		point := ecdhKey.Bytes()[1:]
+		size := len(point) / 2
+
+		return &model.JwkMain{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(point[:size]),
+			Y:   base64.RawURLEncoding.EncodeToString(point[size:]),
+		}, nil
+	case ed25519.PublicKey:
+		return &model.JwkMain{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// jwkVerificationKey parses public key of the jwk
+func jwkVerificationKey(jwk *model.JwkMain) (*model.VerificationKey, error) {
+	result := &model.VerificationKey{
+		Kid: jwk.Kid,
+		Alg: jwk.Alg,
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode n: %w", err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode e: %w", err)
+		}
+
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid rsa key")
+		}
+
+		result.PublicKey = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "EC":
+		var curve elliptic.Curve
+
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, fmt.Errorf("invalid ec key")
+		}
+
+		// uncompressed point: 0x04 || X || Y
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+
+		result.PublicKey, err = ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("ecdsa.ParseUncompressedPublicKey: %w", err)
+		}
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key")
+		}
+
+		result.PublicKey = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+
+	return result, nil
+}
@@ -1,8 +1,7 @@
 package service
 
-import "crypto/rsa"
+import jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
 
 type JwtsServiceI interface {
-	GetPublicKey() *rsa.PublicKey
-	GetKid() string
+	GetKeys() []*jwtsModel.Key
 }
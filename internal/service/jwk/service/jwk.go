@@ -2,77 +2,236 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
 	"reflect"
+	"sync"
+	"time"
 
 	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
 	"github.com/rendau/jwts/internal/service/jwk/model"
 )
 
 type Service struct {
-	jwks *model.JwkSet
+	mu        sync.RWMutex
+	upstreams []*upstream
+	keys      []localJwk
 
 	jwtsService JwtsServiceI
-	eJwkService e_jwk.EJwkServiceI
 }
 
-func New(jwtsService JwtsServiceI, eJwkService e_jwk.EJwkServiceI) *Service {
-	return &Service{
+type upstream struct {
+	service    e_jwk.EJwkServiceI
+	keys       []*model.JwkMain
+	verifyKeys map[string]*model.VerificationKey // by kid
+
+	lastSuccessAt time.Time
+	lastErr       error
+}
+
+type localJwk struct {
+	key       *model.JwkMain
+	expiresAt time.Time
+}
+
+func New(jwtsService JwtsServiceI, eJwkServices []e_jwk.EJwkServiceI) *Service {
+	s := &Service{
 		jwtsService: jwtsService,
-		eJwkService: eJwkService,
 	}
+
+	for _, eJwkService := range eJwkServices {
+		if eJwkService == nil || reflect.ValueOf(eJwkService).IsNil() {
+			continue
+		}
+
+		s.upstreams = append(s.upstreams, &upstream{
+			service: eJwkService,
+		})
+	}
+
+	return s
 }
 
-func (s *Service) CreateJwks() error {
-	var err error
+// RefreshExternalKeys fetches keys of all external jwk services.
+// Returns the smallest upstream max-age hint (zero if absent),
+// on error the last fetched keys of the failed upstream are kept
+func (s *Service) RefreshExternalKeys(ctx context.Context) (time.Duration, error) {
+	var result time.Duration
+	var errList []error
 
-	s.jwks, err = s.createJwks()
-	if err != nil {
-		return err
+	for _, u := range s.upstreams {
+		maxAge, err := s.refreshUpstream(ctx, u)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		if result == 0 || (maxAge > 0 && maxAge < result) {
+			result = maxAge
+		}
 	}
 
-	return nil
+	return result, errors.Join(errList...)
 }
 
-func (s *Service) createJwks() (*model.JwkSet, error) {
-	result := &model.JwkSet{}
+func (s *Service) refreshUpstream(ctx context.Context, u *upstream) (time.Duration, error) {
+	eJwks, maxAge, err := u.service.FetchJwks(ctx)
+	if err != nil {
+		s.mu.Lock()
+		u.lastErr = err
+		s.mu.Unlock()
+
+		return 0, err
+	}
+
+	issuer := u.service.Issuer()
 
-	if !reflect.ValueOf(s.eJwkService).IsNil() {
-		eKeys, err := s.eJwkService.FetchJwks(context.Background())
+	verifyKeys := make(map[string]*model.VerificationKey, len(eJwks.Keys))
+
+	for _, key := range eJwks.Keys {
+		if key.Kid == "" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		verifyKey, err := jwkVerificationKey(key)
 		if err != nil {
-			return nil, err
+			slog.Warn("skip external jwk", "issuer", issuer, "kid", key.Kid, "error", err)
+			continue
+		}
+
+		verifyKey.Issuer = issuer
+
+		verifyKeys[key.Kid] = verifyKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.keys = eJwks.Keys
+	u.verifyKeys = verifyKeys
+	u.lastSuccessAt = time.Now()
+	u.lastErr = nil
+
+	return maxAge, nil
+}
+
+// GetExternalKey returns verification key of the external jwk sets by kid.
+// Upstreams tagged with an issuer serve only tokens with the same "iss" claim
+func (s *Service) GetExternalKey(kid, issuer string) *model.VerificationKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.upstreams {
+		if key := u.verifyKeys[kid]; key != nil && (key.Issuer == "" || key.Issuer == issuer) {
+			return key
 		}
+	}
+
+	return nil
+}
+
+func (s *Service) HasExternal() bool {
+	return len(s.upstreams) > 0
+}
+
+// IsHealthy reports whether the last fetch of every upstream succeeded
+func (s *Service) IsHealthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		for _, v := range eKeys.Keys {
-			result.Keys = append(result.Keys, v)
+	for _, u := range s.upstreams {
+		if u.lastErr != nil || u.lastSuccessAt.IsZero() {
+			return false
 		}
 	}
 
-	if s.jwtsService.GetPublicKey() == nil {
-		if len(result.Keys) != 0 {
-			return result, nil
+	return true
+}
+
+func (s *Service) GetUpstreamStatus() []*model.UpstreamStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*model.UpstreamStatus, 0, len(s.upstreams))
+
+	for _, u := range s.upstreams {
+		item := &model.UpstreamStatus{
+			Name:          u.service.Name(),
+			Issuer:        u.service.Issuer(),
+			Healthy:       u.lastErr == nil && !u.lastSuccessAt.IsZero(),
+			LastSuccessAt: u.lastSuccessAt,
 		}
-		return nil, nil
+
+		if u.lastErr != nil {
+			item.LastError = u.lastErr.Error()
+		}
+
+		result = append(result, item)
 	}
 
-	eBytes := make([]byte, 4, 4)
-	binary.LittleEndian.PutUint32(eBytes, uint32(s.jwtsService.GetPublicKey().E))
+	return result
+}
 
-	key := model.JwkMain{
-		Kty: "RSA",
-		E:   base64.RawURLEncoding.EncodeToString(eBytes[:3]),
-		Kid: s.jwtsService.GetKid(),
-		Alg: "RS256",
-		N:   base64.RawURLEncoding.EncodeToString(s.jwtsService.GetPublicKey().N.Bytes()),
-		Use: "sig",
+// UpdateKeys rebuilds local keys of the set from the keyring
+func (s *Service) UpdateKeys() error {
+	keys, err := s.createLocalJwks()
+	if err != nil {
+		return err
 	}
 
-	result.Keys = append(result.Keys, &key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+
+	return nil
+}
+
+func (s *Service) createLocalJwks() ([]localJwk, error) {
+	keys := s.jwtsService.GetKeys()
+
+	result := make([]localJwk, 0, len(keys))
+
+	for _, k := range keys {
+		key, err := publicKeyJwk(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+
+		key.Kid = k.Kid
+		key.Alg = k.Alg
+		key.Use = "sig"
+
+		result = append(result, localJwk{
+			key:       key,
+			expiresAt: k.ExpiresAt,
+		})
+	}
 
 	return result, nil
 }
 
+// GetSet returns external keys merged with non-expired local keys
 func (s *Service) GetSet() *model.JwkSet {
-	return s.jwks
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	result := &model.JwkSet{
+		Keys: make([]*model.JwkMain, 0, len(s.keys)),
+	}
+
+	for _, u := range s.upstreams {
+		result.Keys = append(result.Keys, u.keys...)
+	}
+
+	for _, k := range s.keys {
+		if k.expiresAt.IsZero() || now.Before(k.expiresAt) {
+			result.Keys = append(result.Keys, k.key)
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
+	"github.com/rendau/jwts/internal/service/jwk/model"
+	jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
+	jwtsServiceP "github.com/rendau/jwts/internal/service/jwts/service"
+)
+
+type fakeEJwk struct {
+	err error
+}
+
+func (f *fakeEJwk) Name() string {
+	return "fake"
+}
+
+func (f *fakeEJwk) Issuer() string {
+	return ""
+}
+
+func (f *fakeEJwk) FetchJwks(ctx context.Context) (*model.JwkSet, time.Duration, error) {
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	return &model.JwkSet{Keys: []*model.JwkMain{{Kid: "ext", Kty: "RSA"}}}, 0, nil
+}
+
+func TestRefreshExternalKeysUpstreamDown(t *testing.T) {
+	key, err := jwtsServiceP.GenerateKey("local", "ES256")
+	require.NoError(t, err)
+	key.Active = true
+
+	jwtsService := jwtsServiceP.New()
+	require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{key}))
+
+	eJwk := &fakeEJwk{err: errors.New("connection refused")}
+
+	s := New(jwtsService, []e_jwk.EJwkServiceI{eJwk})
+
+	require.NoError(t, s.UpdateKeys())
+
+	// local keys are served anyway
+	_, err = s.RefreshExternalKeys(context.Background())
+	require.Error(t, err)
+	require.False(t, s.IsHealthy())
+	require.Len(t, s.GetSet().Keys, 1)
+
+	status := s.GetUpstreamStatus()
+	require.Len(t, status, 1)
+	require.False(t, status[0].Healthy)
+	require.Equal(t, "connection refused", status[0].LastError)
+
+	// recovered
+	eJwk.err = nil
+
+	_, err = s.RefreshExternalKeys(context.Background())
+	require.NoError(t, err)
+	require.True(t, s.IsHealthy())
+	require.Len(t, s.GetSet().Keys, 2)
+	require.False(t, s.GetUpstreamStatus()[0].LastSuccessAt.IsZero())
+}
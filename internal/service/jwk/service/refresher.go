@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	refresherBackoffStart = 5 * time.Second
+	refresherBackoffMax   = 5 * time.Minute
+)
+
+// Refresher periodically re-fetches the external jwk sets.
+// The upstream max-age hint is used as the delay, bounded by minInterval and interval.
+// Failures are retried with exponential backoff, the last fetched keys are served meanwhile.
+// With zero interval only failed fetches are retried, until the first success
+type Refresher struct {
+	jwkService  *Service
+	interval    time.Duration
+	minInterval time.Duration
+	onRefresh   func(err error)
+}
+
+func NewRefresher(jwkService *Service, interval, minInterval time.Duration, onRefresh func(err error)) *Refresher {
+	return &Refresher{
+		jwkService:  jwkService,
+		interval:    interval,
+		minInterval: min(minInterval, interval),
+		onRefresh:   onRefresh,
+	}
+}
+
+func (r *Refresher) Run(ctx context.Context) {
+	backoff := refresherBackoffStart
+
+	backoffMax := r.interval
+	if backoffMax <= 0 {
+		backoffMax = refresherBackoffMax
+	}
+
+	// upstream can be unavailable on startup
+	delay := r.interval
+	if !r.jwkService.IsHealthy() {
+		delay = backoff
+	} else if r.interval <= 0 {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		maxAge, err := r.jwkService.RefreshExternalKeys(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if r.onRefresh != nil {
+			r.onRefresh(err)
+		}
+
+		if err != nil {
+			slog.Error("fail to refresh external jwks", "error", err, "retry_in", backoff.String())
+
+			delay = backoff
+			backoff = min(backoff*2, backoffMax)
+
+			continue
+		}
+
+		if r.interval <= 0 {
+			return
+		}
+
+		backoff = refresherBackoffStart
+
+		delay = r.interval
+		if maxAge > 0 {
+			delay = max(min(maxAge, r.interval), r.minInterval)
+		}
+	}
+}
@@ -1,20 +1,178 @@
 package model
 
+import (
+	"time"
+
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+)
+
 type JwtCreateReq struct {
+	Caller     *callerModel.Caller // authenticated caller, selects the mint policy
 	Sub        string
 	ExpSeconds int64
-	Payload    map[string]any
+	Exp        time.Time      // alternative to ExpSeconds
+	Iss        string         // one of the configured issuers, empty - the default one
+	Aud        []string       // empty - no "aud"
+	Nbf        time.Time      // zero - no "nbf"
+	NbfSeconds int64          // relative to now, alternative to Nbf
+	Iat        time.Time      // zero - now
+	Jti        string         // empty - generated, otherwise prefixed with "<caller method>:<caller id>:"
+	Payload    map[string]any // custom claims, registered ones only by the mint policy
+
+	Typ    string         // "typ" header, e.g. "at+jwt"
+	Cty    string         // "cty" header
+	Crit   []string       // "crit" header, supported extensions set in Header
+	Header map[string]any // extra header parameters, only allowed names
+
+	WithRefresh bool          // issue a refresh token too
+	RefreshTtl  time.Duration // zero - default
 }
 
 type JwtCreateRep struct {
-	Token string
+	Token        string
+	RefreshToken string // only on request
+}
+
+type JwtRefreshReq struct {
+	RefreshToken string
+}
+
+// MintPolicy restricts tokens the caller may create
+type MintPolicy struct {
+	CallerId        string         // "*" - callers without own policy
+	CallerMethod    string         // authentication method of the caller, callers of other methods do not match
+	SubPatterns     []string       // path.Match patterns of "sub", empty - any
+	Audiences       []string       // allowed "aud" values, empty - any
+	MaxExpSeconds   int64          // zero - unlimited, otherwise tokens must expire
+	AllowedClaims   []string       // allowed custom payload keys, empty - any
+	ForcedClaims    map[string]any // set over the payload, custom claims only
+	ForbiddenClaims []string       // custom payload keys
+	PayloadClaims   []string       // registered claims allowed in the payload when the typed field is empty, except iat and jti
+}
+
+// RegisteredClaims are set from the typed fields of JwtCreateReq
+var RegisteredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// JwtExchangeReq - token exchange (RFC 8693), the new token is for the subject of SubjectToken
+type JwtExchangeReq struct {
+	Caller       *callerModel.Caller // must be an OAuth client, the acting party without ActorToken
+	SubjectToken string
+	ActorToken   string   // optional, acting party of the delegation
+	Audiences    []string // requested, all granted ones if empty
+	Scopes       []string // requested, all granted ones if empty; narrowed to the scopes of SubjectToken
+}
+
+type JwtExchangeRep struct {
+	Token      string
+	ExpSeconds int64
+	Scopes     []string
+}
+
+// RefreshFamily is a chain of rotated refresh tokens started by one Create.
+// Only the current token is valid, use of a previous one revokes the family
+type RefreshFamily struct {
+	Id         string
+	Gen        int64               // generation of the current token
+	TokenHash  string              // sha256 hex of the current token
+	MacKey     string              // hmac key of the family tokens, proves that a rotated out token was issued
+	Caller     *callerModel.Caller // creator, its mint policy applies on refresh
+	Sub        string
+	Iss        string
+	Aud        []string
+	Payload    map[string]any
+	Header     map[string]any // header parameters besides "alg" and "kid"
+	ExpSeconds int64          // access token lifetime
+	Ttl        time.Duration  // refresh token lifetime, extended on every rotation
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	Revoked    bool
+
+	// last issued access token, revoked with the family
+	AccessJti       string
+	AccessExpiresAt time.Time
 }
 
 type JwtValidateReq struct {
-	Token string
+	Token                   string
+	IncludeUnverifiedClaims bool // debug, claims of invalid token in JwtValidateRep.UnverifiedClaims
+	ValidatePolicy
+}
+
+// ValidatePolicy is a set of claim checks on top of the signature and "exp"/"nbf" checks
+type ValidatePolicy struct {
+	Issuers        []string // "iss" must be one of
+	Audiences      []string // "aud" must contain one of
+	RequiredClaims []string
+	Leeway         time.Duration // clock skew for time based claims
+	MaxAge         time.Duration // max time since "iat", requires "iat"
+	Strict         *bool         // no claims for invalid tokens
+	Typ            string        // expected "typ" header, case and "application/" prefix are ignored
+}
+
+// Merge returns the policy with empty fields taken from def
+func (p ValidatePolicy) Merge(def ValidatePolicy) ValidatePolicy {
+	if len(p.Issuers) == 0 {
+		p.Issuers = def.Issuers
+	}
+	if len(p.Audiences) == 0 {
+		p.Audiences = def.Audiences
+	}
+	if len(p.RequiredClaims) == 0 {
+		p.RequiredClaims = def.RequiredClaims
+	}
+	if p.Leeway == 0 {
+		p.Leeway = def.Leeway
+	}
+	if p.MaxAge == 0 {
+		p.MaxAge = def.MaxAge
+	}
+	if p.Strict == nil {
+		p.Strict = def.Strict
+	}
+	if p.Typ == "" {
+		p.Typ = def.Typ
+	}
+
+	return p
 }
 
 type JwtValidateRep struct {
 	Valid  bool
 	Claims map[string]any
+	Reason InvalidReason // empty if valid
+	Detail string
+
+	UnverifiedClaims map[string]any // only on request, for invalid tokens
+}
+
+// InvalidReason is a machine-readable cause of the validation failure
+type InvalidReason string
+
+const (
+	InvalidReasonMalformed       InvalidReason = "malformed"
+	InvalidReasonBadSignature    InvalidReason = "bad_signature"
+	InvalidReasonUnknownKid      InvalidReason = "unknown_kid"
+	InvalidReasonAlgMismatch     InvalidReason = "alg_mismatch"
+	InvalidReasonExpired         InvalidReason = "expired"
+	InvalidReasonNotYetValid     InvalidReason = "not_yet_valid"
+	InvalidReasonInvalidIssuer   InvalidReason = "invalid_issuer"
+	InvalidReasonInvalidAudience InvalidReason = "invalid_audience"
+	InvalidReasonMissingClaim    InvalidReason = "missing_claim"
+	InvalidReasonTooOld          InvalidReason = "too_old"
+	InvalidReasonInvalid         InvalidReason = "invalid"
+	InvalidReasonRevoked         InvalidReason = "revoked"
+	InvalidReasonInvalidType     InvalidReason = "invalid_type"
+	InvalidReasonUnsupportedCrit InvalidReason = "unsupported_crit"
+)
+
+type JwtRevokeReq struct {
+	Jti       string
+	ExpiresAt time.Time // expiration of the token, required with Jti
+	Token     string    // alternative to Jti and ExpiresAt
+}
+
+// SubjectCutoff revokes all tokens of the subject issued before the time
+type SubjectCutoff struct {
+	Sub    string
+	Before time.Time // zero - now
 }
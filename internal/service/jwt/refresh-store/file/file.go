@@ -0,0 +1,292 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+	"github.com/rendau/jwts/internal/service/jwt/refresh-store/mem"
+)
+
+// compactMinLines - the log is rewritten when it has more lines than live families plus this value
+const compactMinLines = 1000
+
+// Store keeps refresh token families in memory and appends their snapshots to a local file (json line per change).
+// On start the file is loaded and rewritten without expired families
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	lines int
+
+	mem *mem.Store
+}
+
+type entry struct {
+	Id              string         `json:"id"`
+	Gen             int64          `json:"gen"`
+	TokenHash       string         `json:"token_hash"`
+	MacKey          string         `json:"mac_key,omitempty"`
+	CallerId        string         `json:"caller_id,omitempty"`
+	CallerMethod    string         `json:"caller_method,omitempty"`
+	Sub             string         `json:"sub,omitempty"`
+	Iss             string         `json:"iss,omitempty"`
+	Aud             []string       `json:"aud,omitempty"`
+	Payload         map[string]any `json:"payload,omitempty"`
+	Header          map[string]any `json:"header,omitempty"`
+	ExpSeconds      int64          `json:"exp_seconds,omitempty"`
+	Ttl             int64          `json:"ttl"`        // seconds
+	CreatedAt       int64          `json:"created_at"` // unix time
+	ExpiresAt       int64          `json:"expires_at"` // unix time
+	Revoked         bool           `json:"revoked,omitempty"`
+	AccessJti       string         `json:"access_jti,omitempty"`
+	AccessExpiresAt int64          `json:"access_expires_at,omitempty"` // unix time
+}
+
+func New(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		mem:  mem.New(),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) Create(family *model.RefreshFamily) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.append(family)
+}
+
+func (s *Store) Get(id string) (*model.RefreshFamily, error) {
+	return s.mem.Get(id)
+}
+
+func (s *Store) Update(family *model.RefreshFamily, gen int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.mem.Get(family.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if current == nil || current.Gen != gen || current.Revoked {
+		return false, nil
+	}
+
+	return true, s.append(family)
+}
+
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	family, err := s.mem.Get(id)
+	if err != nil || family == nil || family.Revoked {
+		return err
+	}
+
+	family.Revoked = true
+
+	return s.append(family)
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.Close()
+}
+
+// append writes the family snapshot to the file, then applies it in memory
+func (s *Store) append(family *model.RefreshFamily) error {
+	line, err := json.Marshal(familyToEntry(family))
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write refresh file: %w", err)
+	}
+
+	if err = s.f.Sync(); err != nil {
+		return fmt.Errorf("sync refresh file: %w", err)
+	}
+
+	s.lines++
+
+	if err = s.mem.Create(family); err != nil {
+		return err
+	}
+
+	if s.lines > s.mem.Len()+compactMinLines {
+		if err = s.compact(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		var e entry
+
+		// the last line can be torn by a crash
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Id == "" {
+			continue
+		}
+
+		_ = s.mem.Create(entryToFamily(e))
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("read refresh file: %w", err)
+	}
+
+	return nil
+}
+
+// compact rewrites the file with live families only
+func (s *Store) compact() error {
+	items := s.mem.Items()
+
+	tmpPath := filepath.Join(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp")
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create refresh file: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	for _, family := range items {
+		if err = enc.Encode(familyToEntry(family)); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write refresh file: %w", err)
+	}
+
+	if s.f != nil {
+		_ = s.f.Close()
+	}
+
+	s.f, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open refresh file: %w", err)
+	}
+
+	s.lines = len(items)
+
+	return nil
+}
+
+func familyToEntry(family *model.RefreshFamily) entry {
+	e := entry{
+		Id:         family.Id,
+		Gen:        family.Gen,
+		TokenHash:  family.TokenHash,
+		MacKey:     family.MacKey,
+		Sub:        family.Sub,
+		Iss:        family.Iss,
+		Aud:        family.Aud,
+		Payload:    family.Payload,
+		Header:     family.Header,
+		ExpSeconds: family.ExpSeconds,
+		Ttl:        int64(family.Ttl.Seconds()),
+		CreatedAt:  family.CreatedAt.Unix(),
+		ExpiresAt:  family.ExpiresAt.Unix(),
+		Revoked:    family.Revoked,
+		AccessJti:  family.AccessJti,
+	}
+
+	if family.Caller != nil {
+		e.CallerId = family.Caller.Id
+		e.CallerMethod = family.Caller.Method
+	}
+
+	if !family.AccessExpiresAt.IsZero() {
+		e.AccessExpiresAt = family.AccessExpiresAt.Unix()
+	}
+
+	return e
+}
+
+func entryToFamily(e entry) *model.RefreshFamily {
+	family := &model.RefreshFamily{
+		Id:         e.Id,
+		Gen:        e.Gen,
+		TokenHash:  e.TokenHash,
+		MacKey:     e.MacKey,
+		Sub:        e.Sub,
+		Iss:        e.Iss,
+		Aud:        e.Aud,
+		Payload:    e.Payload,
+		Header:     e.Header,
+		ExpSeconds: e.ExpSeconds,
+		Ttl:        time.Duration(e.Ttl) * time.Second,
+		CreatedAt:  time.Unix(e.CreatedAt, 0),
+		ExpiresAt:  time.Unix(e.ExpiresAt, 0),
+		Revoked:    e.Revoked,
+		AccessJti:  e.AccessJti,
+	}
+
+	if e.CallerId != "" {
+		family.Caller = &callerModel.Caller{
+			Id:     e.CallerId,
+			Method: e.CallerMethod,
+		}
+	}
+
+	if e.AccessExpiresAt > 0 {
+		family.AccessExpiresAt = time.Unix(e.AccessExpiresAt, 0)
+	}
+
+	return family
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "refresh.jsonl")
+
+	s, err := New(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	family := &model.RefreshFamily{
+		Id:        "family-1",
+		Gen:       1,
+		TokenHash: "hash-1",
+		Caller:    &callerModel.Caller{Id: "backend", Method: callerModel.MethodApiKey},
+		Sub:       "user-1",
+		Payload:   map[string]any{"role": "admin"},
+		Ttl:       time.Hour,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	require.NoError(t, s.Create(family))
+	require.NoError(t, s.Create(&model.RefreshFamily{Id: "expired", Gen: 1, ExpiresAt: now.Add(-time.Second)}))
+	require.NoError(t, s.Create(&model.RefreshFamily{Id: "revoked", Gen: 1, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, s.Revoke("revoked"))
+
+	next := *family
+	next.Gen, next.TokenHash = 2, "hash-2"
+
+	ok, err := s.Update(&next, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the generation is already changed
+	ok, err = s.Update(&next, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, s.Close())
+
+	s, err = New(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	loaded, err := s.Get("family-1")
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	require.Equal(t, int64(2), loaded.Gen)
+	require.Equal(t, "hash-2", loaded.TokenHash)
+	require.Equal(t, &callerModel.Caller{Id: "backend", Method: callerModel.MethodApiKey}, loaded.Caller)
+	require.Equal(t, "user-1", loaded.Sub)
+	require.Equal(t, map[string]any{"role": "admin"}, loaded.Payload)
+	require.Equal(t, time.Hour, loaded.Ttl)
+
+	loaded, err = s.Get("revoked")
+	require.NoError(t, err)
+	require.True(t, loaded.Revoked)
+
+	loaded, err = s.Get("expired")
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+}
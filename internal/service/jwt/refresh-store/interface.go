@@ -0,0 +1,13 @@
+package refresh_store
+
+import "github.com/rendau/jwts/internal/service/jwt/model"
+
+type RefreshStoreI interface {
+	Create(family *model.RefreshFamily) error
+	// Get returns nil if the family is not found or expired
+	Get(id string) (*model.RefreshFamily, error)
+	// Update saves the family if the stored generation is still gen, returns false otherwise
+	Update(family *model.RefreshFamily, gen int64) (bool, error)
+	// Revoke marks the family revoked, it is kept until expiration to detect further use
+	Revoke(id string) error
+}
@@ -0,0 +1,119 @@
+package mem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwt/model"
+)
+
+const cleanupInterval = time.Minute
+
+// Store keeps refresh token families in memory, expired families are dropped on the fly
+type Store struct {
+	mu          sync.RWMutex
+	items       map[string]*model.RefreshFamily
+	lastCleanup time.Time
+}
+
+func New() *Store {
+	return &Store{
+		items:       make(map[string]*model.RefreshFamily),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *Store) Create(family *model.RefreshFamily) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(family)
+
+	return nil
+}
+
+func (s *Store) Get(id string) (*model.RefreshFamily, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	family := s.items[id]
+	if family == nil || !time.Now().Before(family.ExpiresAt) {
+		return nil, nil
+	}
+
+	result := *family
+
+	return &result, nil
+}
+
+func (s *Store) Update(family *model.RefreshFamily, gen int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.items[family.Id]
+	if current == nil || current.Gen != gen || current.Revoked {
+		return false, nil
+	}
+
+	s.set(family)
+
+	return true, nil
+}
+
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if family := s.items[id]; family != nil {
+		family.Revoked = true
+	}
+
+	return nil
+}
+
+// Items returns non-expired families
+func (s *Store) Items() []*model.RefreshFamily {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	result := make([]*model.RefreshFamily, 0, len(s.items))
+
+	for _, family := range s.items {
+		if now.Before(family.ExpiresAt) {
+			item := *family
+			result = append(result, &item)
+		}
+	}
+
+	return result
+}
+
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.items)
+}
+
+func (s *Store) set(family *model.RefreshFamily) {
+	item := *family
+	s.items[family.Id] = &item
+
+	now := time.Now()
+
+	if now.Sub(s.lastCleanup) > cleanupInterval {
+		s.cleanup(now)
+	}
+}
+
+func (s *Store) cleanup(now time.Time) {
+	for id, family := range s.items {
+		if !now.Before(family.ExpiresAt) {
+			delete(s.items, id)
+		}
+	}
+
+	s.lastCleanup = now
+}
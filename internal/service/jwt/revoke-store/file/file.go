@@ -0,0 +1,237 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwt/revoke-store/mem"
+)
+
+// compactMinLines - the log is rewritten when it has more lines than live entries plus this value
+const compactMinLines = 1000
+
+// Store keeps revoked jti and subject cutoffs in memory and appends them to a local file (json line per entry).
+// On start the file is loaded and rewritten without expired entries
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	lines int
+
+	mem *mem.Store
+}
+
+type entry struct {
+	Jti    string `json:"jti,omitempty"`
+	Exp    int64  `json:"exp,omitempty"` // unix time
+	Sub    string `json:"sub,omitempty"`
+	Before int64  `json:"before,omitempty"` // unix time
+	Clear  bool   `json:"clear,omitempty"`  // subject cutoff removed
+}
+
+func New(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		mem:  mem.New(),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) Revoke(jti string, expiresAt time.Time) error {
+	e := entry{Jti: jti}
+	if !expiresAt.IsZero() {
+		e.Exp = expiresAt.Unix()
+	}
+
+	return s.append(e, func() error { return s.mem.Revoke(jti, expiresAt) })
+}
+
+func (s *Store) IsRevoked(jti string) (bool, error) {
+	return s.mem.IsRevoked(jti)
+}
+
+func (s *Store) SetSubjectCutoff(sub string, before time.Time) error {
+	e := entry{Sub: sub, Before: before.Unix()}
+
+	return s.append(e, func() error { return s.mem.SetSubjectCutoff(sub, before) })
+}
+
+func (s *Store) GetSubjectCutoff(sub string) (time.Time, error) {
+	return s.mem.GetSubjectCutoff(sub)
+}
+
+func (s *Store) ListSubjectCutoffs() (map[string]time.Time, error) {
+	return s.mem.ListSubjectCutoffs()
+}
+
+func (s *Store) ClearSubjectCutoff(sub string) error {
+	e := entry{Sub: sub, Clear: true}
+
+	return s.append(e, func() error { return s.mem.ClearSubjectCutoff(sub) })
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.Close()
+}
+
+// append writes the entry to the file, then applies it in memory
+func (s *Store) append(e entry, apply func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write revoke file: %w", err)
+	}
+
+	if err = s.f.Sync(); err != nil {
+		return fmt.Errorf("sync revoke file: %w", err)
+	}
+
+	s.lines++
+
+	if err = apply(); err != nil {
+		return err
+	}
+
+	if s.lines > s.mem.Len()+compactMinLines {
+		if err = s.compact(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		var e entry
+
+		// the last line can be torn by a crash
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+
+		switch {
+		case e.Jti != "":
+			var expiresAt time.Time
+			if e.Exp > 0 {
+				expiresAt = time.Unix(e.Exp, 0)
+			}
+
+			_ = s.mem.Revoke(e.Jti, expiresAt)
+		case e.Sub != "" && e.Clear:
+			_ = s.mem.ClearSubjectCutoff(e.Sub)
+		case e.Sub != "":
+			_ = s.mem.SetSubjectCutoff(e.Sub, time.Unix(e.Before, 0))
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("read revoke file: %w", err)
+	}
+
+	return nil
+}
+
+// compact rewrites the file with live entries only
+func (s *Store) compact() error {
+	items := s.mem.Items()
+
+	subjects, err := s.mem.ListSubjectCutoffs()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp")
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create revoke file: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	for jti, expiresAt := range items {
+		e := entry{Jti: jti}
+		if !expiresAt.IsZero() {
+			e.Exp = expiresAt.Unix()
+		}
+
+		if err = enc.Encode(e); err != nil {
+			break
+		}
+	}
+
+	for sub, before := range subjects {
+		if err != nil {
+			break
+		}
+
+		err = enc.Encode(entry{Sub: sub, Before: before.Unix()})
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write revoke file: %w", err)
+	}
+
+	if s.f != nil {
+		_ = s.f.Close()
+	}
+
+	s.f, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open revoke file: %w", err)
+	}
+
+	s.lines = len(items) + len(subjects)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revoked.jsonl")
+
+	s, err := New(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	require.NoError(t, s.Revoke("live", now.Add(time.Hour)))
+	require.NoError(t, s.Revoke("forever", time.Time{}))
+	require.NoError(t, s.Revoke("expired", now.Add(-time.Second)))
+	require.NoError(t, s.SetSubjectCutoff("user-1", now))
+	require.NoError(t, s.SetSubjectCutoff("user-2", now))
+	require.NoError(t, s.ClearSubjectCutoff("user-2"))
+	require.NoError(t, s.Close())
+
+	s, err = New(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	for jti, expected := range map[string]bool{"live": true, "forever": true, "expired": false, "unknown": false} {
+		revoked, err := s.IsRevoked(jti)
+		require.NoError(t, err)
+		require.Equal(t, expected, revoked, jti)
+	}
+
+	cutoffs, err := s.ListSubjectCutoffs()
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Time{"user-1": time.Unix(now.Unix(), 0)}, cutoffs)
+
+	// expired entries are dropped from the file
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 3)
+	require.NotContains(t, string(data), "expired")
+}
@@ -0,0 +1,16 @@
+package revoke_store
+
+import "time"
+
+type RevokeStoreI interface {
+	// Revoke adds jti to the denylist until expiresAt, zero - forever
+	Revoke(jti string, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+
+	// SetSubjectCutoff revokes tokens of the subject issued before the time
+	SetSubjectCutoff(sub string, before time.Time) error
+	// GetSubjectCutoff returns zero time if the subject has no cutoff
+	GetSubjectCutoff(sub string) (time.Time, error)
+	ListSubjectCutoffs() (map[string]time.Time, error)
+	ClearSubjectCutoff(sub string) error
+}
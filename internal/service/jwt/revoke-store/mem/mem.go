@@ -0,0 +1,127 @@
+package mem
+
+import (
+	"sync"
+	"time"
+)
+
+const cleanupInterval = time.Minute
+
+// Store keeps revoked jti and subject cutoffs in memory, expired jti are dropped on the fly
+type Store struct {
+	mu          sync.RWMutex
+	items       map[string]time.Time // jti -> expires at
+	subjects    map[string]time.Time // sub -> cutoff
+	lastCleanup time.Time
+}
+
+func New() *Store {
+	return &Store{
+		items:       make(map[string]time.Time),
+		subjects:    make(map[string]time.Time),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *Store) Revoke(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// keep the longest revocation
+	if current, ok := s.items[jti]; ok && (current.IsZero() || (!expiresAt.IsZero() && current.After(expiresAt))) {
+		expiresAt = current
+	}
+
+	s.items[jti] = expiresAt
+
+	if now.Sub(s.lastCleanup) > cleanupInterval {
+		s.cleanup(now)
+	}
+
+	return nil
+}
+
+func (s *Store) IsRevoked(jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiresAt, ok := s.items[jti]
+
+	return ok && (expiresAt.IsZero() || time.Now().Before(expiresAt)), nil
+}
+
+// Items returns non-expired entries
+func (s *Store) Items() map[string]time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	result := make(map[string]time.Time, len(s.items))
+
+	for jti, expiresAt := range s.items {
+		if expiresAt.IsZero() || now.Before(expiresAt) {
+			result[jti] = expiresAt
+		}
+	}
+
+	return result
+}
+
+// Len returns count of jti and subject entries
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.items) + len(s.subjects)
+}
+
+func (s *Store) SetSubjectCutoff(sub string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subjects[sub] = before
+
+	return nil
+}
+
+func (s *Store) GetSubjectCutoff(sub string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.subjects[sub], nil
+}
+
+func (s *Store) ListSubjectCutoffs() (map[string]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]time.Time, len(s.subjects))
+
+	for sub, before := range s.subjects {
+		result[sub] = before
+	}
+
+	return result, nil
+}
+
+func (s *Store) ClearSubjectCutoff(sub string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subjects, sub)
+
+	return nil
+}
+
+func (s *Store) cleanup(now time.Time) {
+	for jti, expiresAt := range s.items {
+		if !expiresAt.IsZero() && !now.Before(expiresAt) {
+			delete(s.items, jti)
+		}
+	}
+
+	s.lastCleanup = now
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/rendau/jwts/internal/errs"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+)
+
+const (
+	maxJtiLen  = 255
+	maxIatSkew = time.Minute
+)
+
+// servicePayloadClaims are always set by the service, never taken from the payload
+var servicePayloadClaims = []string{"iat", "jti"}
+
+// prepareRegisteredClaims validates the typed registered claims of the create request,
+// relative times are resolved: Exp and ExpSeconds, Nbf and NbfSeconds are filled both.
+// Registered claims in the payload are rejected, except payloadClaims of the mint policy
+func (s *Service) prepareRegisteredClaims(obj *model.JwtCreateReq, payloadClaims []string, now time.Time) error {
+	fields := map[string]string{}
+
+	if obj.Iss != "" && !slices.Contains(s.issuers, obj.Iss) {
+		fields["iss"] = "must be one of the configured issuers"
+	}
+
+	if slices.Contains(obj.Aud, "") {
+		fields["aud"] = "must not contain empty values"
+	}
+
+	if !obj.Exp.IsZero() {
+		if obj.ExpSeconds != 0 {
+			fields["exp"] = "exclusive with exp_seconds"
+		} else if !obj.Exp.After(now) {
+			fields["exp"] = "must be in the future"
+		} else {
+			obj.ExpSeconds = obj.Exp.Unix() - now.Unix()
+		}
+	} else if obj.ExpSeconds > 0 {
+		obj.Exp = time.Unix(now.Unix()+obj.ExpSeconds, 0)
+	}
+
+	if obj.NbfSeconds != 0 {
+		if !obj.Nbf.IsZero() {
+			fields["nbf"] = "exclusive with nbf_seconds"
+		} else {
+			obj.Nbf = time.Unix(now.Unix()+obj.NbfSeconds, 0)
+		}
+	}
+
+	if _, ok := fields["nbf"]; !ok && !obj.Nbf.IsZero() {
+		if obj.Nbf.Unix() <= 0 {
+			fields["nbf"] = "must be positive unix time"
+		} else if !obj.Exp.IsZero() && !obj.Nbf.Before(obj.Exp) {
+			fields["nbf"] = "must be before exp"
+		}
+	}
+
+	if !obj.Iat.IsZero() {
+		if obj.Iat.Unix() <= 0 {
+			fields["iat"] = "must be positive unix time"
+		} else if obj.Iat.After(now.Add(maxIatSkew)) {
+			fields["iat"] = "must not be in the future"
+		}
+	}
+
+	// jti of the caller is namespaced, it can not collide with generated ones or ones of other callers
+	if obj.Jti != "" {
+		if obj.Caller == nil {
+			fields["jti"] = "requires an authenticated caller"
+		} else if len(obj.Jti) > maxJtiLen {
+			fields["jti"] = fmt.Sprintf("must be at most %d characters", maxJtiLen)
+		} else {
+			obj.Jti = obj.Caller.Method + ":" + obj.Caller.Id + ":" + obj.Jti
+		}
+	}
+
+	for k, v := range obj.Payload {
+		if !slices.Contains(model.RegisteredClaims, k) {
+			continue
+		}
+
+		if slices.Contains(payloadClaims, k) && !slices.Contains(servicePayloadClaims, k) {
+			if iss, ok := v.(string); k == "iss" && obj.Iss == "" && (!ok || !slices.Contains(s.issuers, iss)) {
+				fields[k] = "must be one of the configured issuers"
+			}
+
+			continue
+		}
+
+		// same value as the typed field
+		if k == "sub" && v == obj.Sub {
+			continue
+		}
+
+		fields[k] = "registered claim, use the typed field"
+	}
+
+	if len(fields) > 0 {
+		return errs.ErrFull{
+			Err:    errs.InvalidRequest,
+			Desc:   "invalid registered claims",
+			Fields: fields,
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) defaultIssuer() string {
+	if len(s.issuers) == 0 {
+		return ""
+	}
+
+	return s.issuers[0]
+}
+
+// buildClaims sets the registered claims over the payload.
+// Registered claims allowed in the payload are kept if the typed field is empty
+func (s *Service) buildClaims(obj *model.JwtCreateReq, now time.Time) (jwt.MapClaims, error) {
+	claims := make(jwt.MapClaims, len(obj.Payload)+7)
+
+	for k, v := range obj.Payload {
+		claims[k] = v
+	}
+
+	if obj.Iss != "" {
+		claims["iss"] = obj.Iss
+	} else if _, ok := claims["iss"]; !ok {
+		claims["iss"] = s.defaultIssuer()
+	}
+
+	claims["sub"] = obj.Sub
+
+	if len(obj.Aud) > 0 {
+		claims["aud"] = obj.Aud
+	}
+
+	if !obj.Exp.IsZero() {
+		claims["exp"] = obj.Exp.Unix()
+	} else if obj.ExpSeconds > 0 {
+		claims["exp"] = now.Unix() + obj.ExpSeconds
+	}
+
+	if !obj.Nbf.IsZero() {
+		claims["nbf"] = obj.Nbf.Unix()
+	}
+
+	iat := now.Add(-5 * time.Second).Unix()
+	if !obj.Iat.IsZero() {
+		iat = obj.Iat.Unix()
+	}
+
+	// tokens issued after the subject revocation must not be backdated before its cutoff
+	if s.revokeStore != nil && obj.Sub != "" {
+		cutoff, err := s.revokeStore.GetSubjectCutoff(obj.Sub)
+		if err != nil {
+			return nil, fmt.Errorf("revokeStore.GetSubjectCutoff: %w", err)
+		}
+
+		if !cutoff.IsZero() {
+			iat = max(iat, cutoff.Unix())
+		}
+	}
+
+	claims["iat"] = iat
+
+	if obj.Jti != "" {
+		claims["jti"] = obj.Jti
+	} else {
+		jti, err := newJti() // token id, for revocation
+		if err != nil {
+			return nil, err
+		}
+
+		claims["jti"] = jti
+	}
+
+	return claims, nil
+}
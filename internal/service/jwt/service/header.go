@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rendau/jwts/internal/errs"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+)
+
+var (
+	errInvalidType     = errors.New("token type is invalid")
+	errUnsupportedCrit = errors.New("unsupported critical header")
+)
+
+// reservedHeaders are set by the service or from the typed fields of JwtCreateReq
+var reservedHeaders = []string{"alg", "kid", "typ", "cty", "crit"}
+
+// prepareHeader validates the header fields of the create request,
+// returns header parameters besides "alg" and "kid"
+func (s *Service) prepareHeader(obj *model.JwtCreateReq) (map[string]any, error) {
+	fields := map[string]string{}
+
+	for k := range obj.Header {
+		switch {
+		case slices.Contains(reservedHeaders, k):
+			fields["header."+k] = "reserved header parameter"
+		case !slices.Contains(s.extraHeaders, k):
+			fields["header."+k] = "not allowed"
+		}
+	}
+
+	for _, name := range obj.Crit {
+		if !slices.Contains(s.critHeaders, name) || slices.Contains(reservedHeaders, name) {
+			fields["crit"] = fmt.Sprintf("unsupported extension %q", name)
+		} else if _, ok := obj.Header[name]; !ok {
+			fields["crit"] = fmt.Sprintf("extension %q is not set in the header", name)
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, errs.ErrFull{
+			Err:    errs.InvalidRequest,
+			Desc:   "invalid header",
+			Fields: fields,
+		}
+	}
+
+	result := maps.Clone(obj.Header)
+	if result == nil {
+		result = map[string]any{}
+	}
+
+	if obj.Typ != "" {
+		result["typ"] = obj.Typ
+	}
+
+	if obj.Cty != "" {
+		result["cty"] = obj.Cty
+	}
+
+	if len(obj.Crit) > 0 {
+		result["crit"] = obj.Crit
+	}
+
+	return result, nil
+}
+
+// checkHeader checks the expected "typ" and the critical extensions of the verified token (RFC 7515 4.1.11)
+func checkHeader(header map[string]any, typ string, critHeaders []string) error {
+	if v, ok := header["crit"]; ok {
+		names, ok := v.([]any)
+		if !ok || len(names) == 0 {
+			return fmt.Errorf("%w: malformed crit", errUnsupportedCrit)
+		}
+
+		for _, item := range names {
+			name, _ := item.(string)
+
+			if !slices.Contains(critHeaders, name) || slices.Contains(reservedHeaders, name) {
+				return fmt.Errorf("%w: %q", errUnsupportedCrit, name)
+			}
+
+			if _, ok = header[name]; !ok {
+				return fmt.Errorf("%w: %q is not set", errUnsupportedCrit, name)
+			}
+		}
+	}
+
+	if typ != "" {
+		got, _ := header["typ"].(string)
+
+		if !strings.EqualFold(mediaType(got), mediaType(typ)) {
+			return fmt.Errorf("%w: %q", errInvalidType, got)
+		}
+	}
+
+	return nil
+}
+
+// mediaType omits the "application/" prefix, as allowed for "typ" (RFC 7515 4.1.9)
+func mediaType(v string) string {
+	if len(v) > len("application/") && strings.EqualFold(v[:len("application/")], "application/") {
+		return v[len("application/"):]
+	}
+
+	return v
+}
@@ -1,9 +1,23 @@
 package service
 
-import "crypto/rsa"
+import (
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+	jwkModel "github.com/rendau/jwts/internal/service/jwk/model"
+	jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
+)
 
 type JwtsServiceI interface {
-	GetPrivateKey() *rsa.PrivateKey
-	GetPublicKey() *rsa.PublicKey
-	GetKid() string
+	GetActiveKey() *jwtsModel.Key
+	GetKey(kid string) *jwtsModel.Key
+	GetKeys() []*jwtsModel.Key
+}
+
+type JwkServiceI interface {
+	HasExternal() bool
+	GetExternalKey(kid, issuer string) *jwkModel.VerificationKey
+}
+
+type ClientServiceI interface {
+	Get(id string) *clientModel.Client
+	Grant(c *clientModel.Client, grantType string, scopes, audiences []string) (*clientModel.Grant, error)
 }
@@ -1,86 +1,854 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
-	"github.com/rendau/jwts/internal/constant"
 	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
 	"github.com/rendau/jwts/internal/service/jwt/model"
+	refresh_store "github.com/rendau/jwts/internal/service/jwt/refresh-store"
+	revoke_store "github.com/rendau/jwts/internal/service/jwt/revoke-store"
+)
+
+var (
+	errUnknownKid  = errors.New("unknown kid")
+	errAlgMismatch = errors.New("signing algorithm does not match the key")
+	errTokenTooOld = errors.New("token is too old")
+	errRevoked     = errors.New("token is revoked")
 )
 
 type Service struct {
 	jwtsService   JwtsServiceI
-	defaultIssuer string
+	jwkService    JwkServiceI
+	revokeStore   revoke_store.RevokeStoreI
+	refreshStore  refresh_store.RefreshStoreI
+	clientService ClientServiceI
+	issuers       []string
+	defaultPolicy model.ValidatePolicy
+	refreshTtl    time.Duration
+	mintPolicies  []*model.MintPolicy
+	extraHeaders  []string
+	critHeaders   []string
 }
 
-func New(jwtsService JwtsServiceI, defaultIssuer string) *Service {
+// Options of the service, only JwtsService is required
+type Options struct {
+	JwtsService   JwtsServiceI
+	JwkService    JwkServiceI
+	RevokeStore   revoke_store.RevokeStoreI
+	RefreshStore  refresh_store.RefreshStoreI
+	ClientService ClientServiceI // grants of token exchange
+	Issuers       []string       // allowed "iss" of created tokens, the first is the default
+	DefaultPolicy model.ValidatePolicy
+	RefreshTtl    time.Duration
+	MintPolicies  []*model.MintPolicy
+	ExtraHeaders  []string // header parameters allowed in JwtCreateReq.Header
+	CritHeaders   []string // supported critical extensions
+}
+
+func New(opts Options) *Service {
 	return &Service{
-		jwtsService:   jwtsService,
-		defaultIssuer: defaultIssuer,
+		jwtsService:   opts.JwtsService,
+		jwkService:    opts.JwkService,
+		revokeStore:   opts.RevokeStore,
+		refreshStore:  opts.RefreshStore,
+		clientService: opts.ClientService,
+		issuers:       opts.Issuers,
+		defaultPolicy: opts.DefaultPolicy,
+		refreshTtl:    opts.RefreshTtl,
+		mintPolicies:  opts.MintPolicies,
+		extraHeaders:  opts.ExtraHeaders,
+		critHeaders:   opts.CritHeaders,
 	}
 }
 
 func (s *Service) Create(obj *model.JwtCreateReq) (model.JwtCreateRep, error) {
-	var err error
+	result := model.JwtCreateRep{}
+
+	if obj.WithRefresh && s.refreshStore == nil {
+		return result, fmt.Errorf("refresh tokens are not configured")
+	}
+
+	policy := s.mintPolicy(obj.Caller)
+
+	var payloadClaims []string
+	if policy != nil {
+		payloadClaims = policy.PayloadClaims
+	}
+
+	req := *obj
+
+	if err := s.prepareRegisteredClaims(&req, payloadClaims, time.Now()); err != nil {
+		return result, err
+	}
+
+	header, err := s.prepareHeader(&req)
+	if err != nil {
+		return result, err
+	}
+
+	if policy != nil {
+		req.Payload, err = applyMintPolicy(policy, &req)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	token, jti, expiresAt, err := s.sign(&req, header)
+	if err != nil || token == "" {
+		return result, err
+	}
+
+	result.Token = token
+
+	if obj.WithRefresh {
+		now := time.Now()
+
+		family := &model.RefreshFamily{
+			Caller:          obj.Caller,
+			Sub:             req.Sub,
+			Iss:             req.Iss,
+			Aud:             req.Aud,
+			Payload:         req.Payload,
+			Header:          header,
+			ExpSeconds:      req.ExpSeconds,
+			Ttl:             obj.RefreshTtl,
+			CreatedAt:       now,
+			AccessJti:       jti,
+			AccessExpiresAt: expiresAt,
+		}
+
+		if family.Ttl <= 0 {
+			family.Ttl = s.refreshTtl
+		}
+
+		family.Id, err = newJti()
+		if err != nil {
+			return result, err
+		}
+
+		family.MacKey, err = newMacKey()
+		if err != nil {
+			return result, err
+		}
+
+		result.RefreshToken, err = nextRefreshToken(family, now)
+		if err != nil {
+			return result, err
+		}
+
+		if err = s.refreshStore.Create(family); err != nil {
+			return result, fmt.Errorf("refreshStore.Create: %w", err)
+		}
+	}
+
+	return result, nil
+}
 
+// sign creates a token signed by the active key, empty token if there is no active key.
+// header - parameters besides "alg" and "kid"
+func (s *Service) sign(obj *model.JwtCreateReq, header map[string]any) (string, string, time.Time, error) {
+	var expiresAt time.Time
+
+	key := s.jwtsService.GetActiveKey()
+	if key == nil {
+		return "", "", expiresAt, nil
+	}
+
+	claims, err := s.buildClaims(obj, time.Now())
+	if err != nil {
+		return "", "", expiresAt, err
+	}
+
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		expiresAt = exp.Time
+	}
+
+	jti, _ := claims["jti"].(string)
+
+	t := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims)
+
+	for k, v := range header {
+		t.Header[k] = v
+	}
+
+	if key.Kid != "" {
+		t.Header["kid"] = key.Kid
+	}
+
+	token, err := t.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", "", expiresAt, fmt.Errorf("t.SignedString: %w", err)
+	}
+
+	return token, jti, expiresAt, nil
+}
+
+// Refresh exchanges the current refresh token of a family for a new access/refresh pair.
+// Use of a rotated out token revokes the family and its last access token
+func (s *Service) Refresh(obj *model.JwtRefreshReq) (model.JwtCreateRep, error) {
 	result := model.JwtCreateRep{}
 
-	if s.jwtsService.GetPrivateKey() == nil {
-		return result, nil
+	if s.refreshStore == nil {
+		return result, fmt.Errorf("refresh tokens are not configured")
 	}
 
-	claims := jwt.MapClaims{
-		"iss": s.defaultIssuer, // issuer
+	id, gen, ok := parseRefreshToken(obj.RefreshToken)
+	if !ok {
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "malformed refresh token"}
 	}
 
-	for k, v := range obj.Payload {
-		claims[k] = v
+	family, err := s.refreshStore.Get(id)
+	if err != nil {
+		return result, fmt.Errorf("refreshStore.Get: %w", err)
 	}
 
-	now := time.Now()
+	if family == nil {
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is expired or unknown"}
+	}
+
+	if family.Revoked {
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is revoked"}
+	}
+
+	// only a token really issued in the family proves the reuse, forged ones must not revoke it
+	if gen < family.Gen && refreshTokenIssued(family, obj.RefreshToken) {
+		slog.Warn("refresh token reuse, family revoked", "sub", family.Sub)
 
-	if obj.ExpSeconds > 0 {
-		claims["exp"] = now.Unix() + obj.ExpSeconds // expiration time
+		if err = s.revokeRefreshFamily(family.Id); err != nil {
+			return result, err
+		}
+
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is already used"}
 	}
 
-	claims["iat"] = now.Add(-5 * time.Second).Unix() // issued at
-	claims["sub"] = obj.Sub                          // subject (user id)
+	tokenHash := sha256.Sum256([]byte(obj.RefreshToken))
 
-	t := jwt.NewWithClaims(jwt.GetSigningMethod(constant.JwtSigningMethod), claims)
+	if gen != family.Gen || subtle.ConstantTimeCompare([]byte(hex.EncodeToString(tokenHash[:])), []byte(family.TokenHash)) != 1 {
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is expired or unknown"}
+	}
+
+	// subject revocation covers refresh tokens issued before its cutoff
+	if s.revokeStore != nil && family.Sub != "" {
+		cutoff, err := s.revokeStore.GetSubjectCutoff(family.Sub)
+		if err != nil {
+			return result, fmt.Errorf("revokeStore.GetSubjectCutoff: %w", err)
+		}
+
+		if !cutoff.IsZero() && family.CreatedAt.Unix() < cutoff.Unix() {
+			if err = s.refreshStore.Revoke(family.Id); err != nil {
+				return result, fmt.Errorf("refreshStore.Revoke: %w", err)
+			}
+
+			return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is revoked"}
+		}
+	}
+
+	req := &model.JwtCreateReq{
+		Caller:     family.Caller,
+		Sub:        family.Sub,
+		ExpSeconds: family.ExpSeconds,
+		Iss:        family.Iss,
+		Aud:        family.Aud,
+		Payload:    family.Payload,
+	}
+
+	// the policy may have changed since the family was created
+	if policy := s.mintPolicy(family.Caller); policy != nil {
+		req.Payload, err = applyMintPolicy(policy, req)
+		if err != nil {
+			return result, err
+		}
+	}
 
-	if s.jwtsService.GetKid() != "" {
-		t.Header["kid"] = s.jwtsService.GetKid()
+	token, jti, expiresAt, err := s.sign(req, family.Header)
+	if err != nil || token == "" {
+		return result, err
 	}
 
-	result.Token, err = t.SignedString(s.jwtsService.GetPrivateKey())
+	next := *family
+	next.AccessJti = jti
+	next.AccessExpiresAt = expiresAt
+
+	refreshToken, err := nextRefreshToken(&next, time.Now())
 	if err != nil {
-		return result, fmt.Errorf("t.SignedString: %w", err)
+		return result, err
+	}
+
+	ok, err = s.refreshStore.Update(&next, family.Gen)
+	if err != nil {
+		return result, fmt.Errorf("refreshStore.Update: %w", err)
+	}
+
+	// concurrent use of the same token
+	if !ok {
+		slog.Warn("refresh token reuse, family revoked", "sub", family.Sub)
+
+		if s.revokeStore != nil {
+			if err = s.revokeStore.Revoke(jti, expiresAt); err != nil {
+				return result, fmt.Errorf("revokeStore.Revoke: %w", err)
+			}
+		}
+
+		if err = s.revokeRefreshFamily(family.Id); err != nil {
+			return result, err
+		}
+
+		return result, errs.ErrFull{Err: errs.InvalidGrant, Desc: "refresh token is already used"}
 	}
 
+	result.Token = token
+	result.RefreshToken = refreshToken
+
 	return result, nil
 }
 
+// revokeRefreshFamily revokes the family and its last access token
+func (s *Service) revokeRefreshFamily(id string) error {
+	if err := s.refreshStore.Revoke(id); err != nil {
+		return fmt.Errorf("refreshStore.Revoke: %w", err)
+	}
+
+	family, err := s.refreshStore.Get(id)
+	if err != nil {
+		return fmt.Errorf("refreshStore.Get: %w", err)
+	}
+
+	if s.revokeStore != nil && family != nil && family.AccessJti != "" {
+		if err = s.revokeStore.Revoke(family.AccessJti, family.AccessExpiresAt); err != nil {
+			return fmt.Errorf("revokeStore.Revoke: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Service) Validate(obj *model.JwtValidateReq) (*model.JwtValidateRep, error) {
 	result := &model.JwtValidateRep{}
 
-	if s.jwtsService.GetPublicKey() == nil {
-		return nil, fmt.Errorf("public key is nil")
+	if len(s.jwtsService.GetKeys()) == 0 && (s.jwkService == nil || !s.jwkService.HasExternal()) {
+		return nil, fmt.Errorf("no verification keys")
+	}
+
+	policy := obj.ValidatePolicy.Merge(s.defaultPolicy)
+
+	opts := []jwt.ParserOption{
+		jwt.WithLeeway(policy.Leeway),
+	}
+
+	if len(policy.Audiences) > 0 {
+		opts = append(opts, jwt.WithAudience(policy.Audiences...))
+	}
+
+	if policy.MaxAge > 0 {
+		opts = append(opts, jwt.WithIssuedAt())
 	}
 
 	claims := jwt.MapClaims{}
 
-	_, err := jwt.ParseWithClaims(obj.Token, &claims, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, errs.InvalidToken
+	token, err := jwt.ParseWithClaims(obj.Token, &claims, s.getVerificationKey, opts...)
+	if err == nil {
+		err = checkHeader(token.Header, policy.Typ, s.critHeaders)
+	}
+	if err == nil {
+		err = checkPolicy(claims, policy, time.Now())
+	}
+	if err == nil {
+		var revoked bool
+
+		revoked, err = s.isRevoked(claims)
+		if err != nil {
+			return nil, err
 		}
-		return s.jwtsService.GetPublicKey(), nil
-	})
+
+		if revoked {
+			err = errRevoked
+		}
+	}
 	result.Valid = err == nil
 
+	if err != nil {
+		result.Reason = invalidReason(err)
+		result.Detail = err.Error()
+
+		if obj.IncludeUnverifiedClaims {
+			result.UnverifiedClaims = claims
+		}
+
+		// claims of invalid token can not be trusted
+		if policy.Strict != nil && *policy.Strict {
+			return result, nil
+		}
+	}
+
 	result.Claims = claims
 
 	return result, nil
 }
+
+// Exchange mints a token for the subject of a valid subject token (RFC 8693).
+// The caller must be a client with the token exchange grant, audiences, scopes and lifetime are limited by it.
+// Custom claims of the subject token are kept, the acting party is added on top of its "act" claim chain
+func (s *Service) Exchange(obj *model.JwtExchangeReq) (model.JwtExchangeRep, error) {
+	result := model.JwtExchangeRep{}
+
+	grant, err := s.exchangeGrant(obj)
+	if err != nil {
+		return result, err
+	}
+
+	if len(grant.Audiences) == 0 {
+		return result, errs.ErrFull{Err: errs.InvalidTarget, Desc: "audience is required"}
+	}
+
+	if grant.TokenTtl <= 0 {
+		return result, fmt.Errorf("token ttl of client %q is not configured", grant.ClientId)
+	}
+
+	subjectClaims, err := s.exchangeTokenClaims(obj.SubjectToken, "subject_token")
+	if err != nil {
+		return result, err
+	}
+
+	actor := map[string]any{"client_id": grant.ClientId}
+
+	if obj.ActorToken != "" {
+		actorClaims, err := s.exchangeTokenClaims(obj.ActorToken, "actor_token")
+		if err != nil {
+			return result, err
+		}
+
+		actorSub, _ := actorClaims.GetSubject()
+		if actorSub == "" {
+			return result, errs.ErrFull{Err: errs.InvalidRequest, Desc: "actor_token: sub is required"}
+		}
+
+		actor = map[string]any{"sub": actorSub}
+	}
+
+	payload := make(map[string]any, len(subjectClaims))
+
+	for k, v := range subjectClaims {
+		if !slices.Contains(exchangeDroppedClaims, k) {
+			payload[k] = v
+		}
+	}
+
+	if prevActor, ok := subjectClaims["act"]; ok {
+		actor["act"] = prevActor
+	}
+
+	payload["act"] = actor
+	payload["client_id"] = grant.ClientId
+
+	// scopes can only be narrowed
+	subjectScope, _ := subjectClaims["scope"].(string)
+
+	for _, scope := range strings.Fields(subjectScope) {
+		if slices.Contains(grant.Scopes, scope) {
+			result.Scopes = append(result.Scopes, scope)
+		}
+	}
+
+	if len(result.Scopes) > 0 {
+		payload["scope"] = strings.Join(result.Scopes, " ")
+	}
+
+	result.ExpSeconds = int64(grant.TokenTtl.Seconds())
+
+	if exp, _ := subjectClaims.GetExpirationTime(); exp != nil {
+		remaining := exp.Unix() - time.Now().Unix()
+		if remaining <= 0 {
+			return result, errs.ErrFull{Err: errs.InvalidRequest, Desc: "subject_token: token is expired"}
+		}
+
+		result.ExpSeconds = min(result.ExpSeconds, remaining)
+	}
+
+	sub, _ := subjectClaims.GetSubject()
+
+	createRep, err := s.Create(&model.JwtCreateReq{
+		Caller:     obj.Caller,
+		Sub:        sub,
+		ExpSeconds: result.ExpSeconds,
+		Aud:        grant.Audiences,
+		Payload:    payload,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if createRep.Token == "" {
+		return result, fmt.Errorf("no active signing key")
+	}
+
+	result.Token = createRep.Token
+
+	return result, nil
+}
+
+// exchangeGrant checks the token exchange grant of the calling client,
+// callers authenticated otherwise (api keys, certificates) are not clients even with the same id
+func (s *Service) exchangeGrant(obj *model.JwtExchangeReq) (*clientModel.Grant, error) {
+	var client *clientModel.Client
+	if s.clientService != nil && obj.Caller != nil && obj.Caller.Method == callerModel.MethodClient {
+		client = s.clientService.Get(obj.Caller.Id)
+	}
+
+	if client == nil {
+		return nil, errs.ErrFull{Err: errs.UnauthorizedClient, Desc: "caller is not a client"}
+	}
+
+	return s.clientService.Grant(client, clientModel.GrantTypeTokenExchange, obj.Scopes, obj.Audiences)
+}
+
+// exchangeDroppedClaims are not copied from the subject token into the exchanged one
+var exchangeDroppedClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "scope", "act", "client_id", "azp"}
+
+// exchangeTokenClaims validates the subject or actor token in strict mode
+func (s *Service) exchangeTokenClaims(token, name string) (jwt.MapClaims, error) {
+	if token == "" {
+		return nil, errs.ErrFull{Err: errs.InvalidRequest, Desc: name + " is required"}
+	}
+
+	strict := true
+
+	rep, err := s.Validate(&model.JwtValidateReq{
+		Token:          token,
+		ValidatePolicy: model.ValidatePolicy{Strict: &strict},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !rep.Valid {
+		return nil, errs.ErrFull{Err: errs.InvalidRequest, Desc: name + ": " + rep.Detail}
+	}
+
+	return rep.Claims, nil
+}
+
+// Revoke adds the token id into the denylist until the token expiration
+func (s *Service) Revoke(obj *model.JwtRevokeReq) error {
+	if s.revokeStore == nil {
+		return fmt.Errorf("revocation is not configured")
+	}
+
+	jti, expiresAt := obj.Jti, obj.ExpiresAt
+
+	if obj.Token != "" {
+		claims := jwt.MapClaims{}
+
+		// expired tokens need no revocation, but are not an error
+		_, err := jwt.ParseWithClaims(obj.Token, &claims, s.getVerificationKey, jwt.WithoutClaimsValidation())
+		if err != nil {
+			return errs.ErrFull{Err: errs.InvalidToken, Desc: err.Error()}
+		}
+
+		jti, _ = claims["jti"].(string)
+
+		exp, _ := claims.GetExpirationTime()
+		if exp != nil {
+			expiresAt = exp.Time
+		}
+	}
+
+	if jti == "" {
+		return errs.ErrFull{Err: errs.InvalidToken, Desc: "jti is required"}
+	}
+
+	// the entry of a bare jti must expire, only tokens without "exp" are denied forever
+	if obj.Token == "" && expiresAt.IsZero() {
+		return errs.ErrFull{
+			Err:    errs.InvalidRequest,
+			Desc:   "expiration of the revoked token is required",
+			Fields: map[string]string{"exp": "required with jti"},
+		}
+	}
+
+	return s.revokeStore.Revoke(jti, expiresAt)
+}
+
+// RevokeSubject revokes all tokens of the subject issued before the cutoff
+func (s *Service) RevokeSubject(obj *model.SubjectCutoff) error {
+	if s.revokeStore == nil {
+		return fmt.Errorf("revocation is not configured")
+	}
+
+	if obj.Sub == "" {
+		return errs.ErrFull{Err: errs.InvalidRequest, Desc: "sub is required"}
+	}
+
+	now := time.Now()
+
+	// a future cutoff would reject tokens issued after the revocation
+	if obj.Before.After(now) {
+		return errs.ErrFull{
+			Err:    errs.InvalidRequest,
+			Desc:   "invalid cutoff",
+			Fields: map[string]string{"before": "must not be in the future"},
+		}
+	}
+
+	before := obj.Before
+	if before.IsZero() {
+		before = now
+	}
+
+	return s.revokeStore.SetSubjectCutoff(obj.Sub, before.Truncate(time.Second))
+}
+
+func (s *Service) ListSubjectCutoffs() ([]*model.SubjectCutoff, error) {
+	if s.revokeStore == nil {
+		return []*model.SubjectCutoff{}, nil
+	}
+
+	items, err := s.revokeStore.ListSubjectCutoffs()
+	if err != nil {
+		return nil, fmt.Errorf("revokeStore.ListSubjectCutoffs: %w", err)
+	}
+
+	result := make([]*model.SubjectCutoff, 0, len(items))
+
+	for sub, before := range items {
+		result = append(result, &model.SubjectCutoff{
+			Sub:    sub,
+			Before: before,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b *model.SubjectCutoff) int { return strings.Compare(a.Sub, b.Sub) })
+
+	return result, nil
+}
+
+func (s *Service) ClearSubjectCutoff(sub string) error {
+	if s.revokeStore == nil {
+		return fmt.Errorf("revocation is not configured")
+	}
+
+	return s.revokeStore.ClearSubjectCutoff(sub)
+}
+
+// isRevoked checks the token id denylist and the subject cutoff
+func (s *Service) isRevoked(claims jwt.MapClaims) (bool, error) {
+	if s.revokeStore == nil {
+		return false, nil
+	}
+
+	if jti, _ := claims["jti"].(string); jti != "" {
+		revoked, err := s.revokeStore.IsRevoked(jti)
+		if err != nil {
+			return false, fmt.Errorf("revokeStore.IsRevoked: %w", err)
+		}
+
+		if revoked {
+			return true, nil
+		}
+	}
+
+	if sub, _ := claims.GetSubject(); sub != "" {
+		cutoff, err := s.revokeStore.GetSubjectCutoff(sub)
+		if err != nil {
+			return false, fmt.Errorf("revokeStore.GetSubjectCutoff: %w", err)
+		}
+
+		if !cutoff.IsZero() {
+			iat, _ := claims.GetIssuedAt()
+			if iat == nil || iat.Unix() < cutoff.Unix() {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// checkPolicy checks the claims not covered by the parser options
+func checkPolicy(claims jwt.MapClaims, policy model.ValidatePolicy, now time.Time) error {
+	if len(policy.Issuers) > 0 {
+		iss, _ := claims.GetIssuer()
+		if !slices.Contains(policy.Issuers, iss) {
+			return fmt.Errorf("%w: %q", jwt.ErrTokenInvalidIssuer, iss)
+		}
+	}
+
+	for _, name := range policy.RequiredClaims {
+		if _, ok := claims[name]; !ok {
+			return fmt.Errorf("%w: %s", jwt.ErrTokenRequiredClaimMissing, name)
+		}
+	}
+
+	if policy.MaxAge > 0 {
+		iat, err := claims.GetIssuedAt()
+		if err != nil || iat == nil {
+			return fmt.Errorf("%w: iat", jwt.ErrTokenRequiredClaimMissing)
+		}
+
+		if now.Sub(iat.Time) > policy.MaxAge+policy.Leeway {
+			return errTokenTooOld
+		}
+	}
+
+	return nil
+}
+
+// getVerificationKey picks the key by token kid header: local keys first, then keys of the external jwk sets
+// matching the token issuer. Tokens without kid are checked against the active key
+func (s *Service) getVerificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key := s.jwtsService.GetKey(kid)
+	if key == nil && kid == "" {
+		key = s.jwtsService.GetActiveKey()
+	}
+
+	if key != nil {
+		if token.Method.Alg() != key.Alg {
+			return nil, errAlgMismatch
+		}
+		return key.PublicKey, nil
+	}
+
+	if kid != "" && s.jwkService != nil {
+		issuer, _ := token.Claims.GetIssuer()
+
+		if eKey := s.jwkService.GetExternalKey(kid, issuer); eKey != nil {
+			// without alg in jwk, the signing method checks the key type itself
+			if eKey.Alg != "" && token.Method.Alg() != eKey.Alg {
+				return nil, errAlgMismatch
+			}
+			return eKey.PublicKey, nil
+		}
+	}
+
+	return nil, errUnknownKid
+}
+
+// invalidReason maps the validation error, signature failures take precedence over claim failures
+func invalidReason(err error) model.InvalidReason {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return model.InvalidReasonMalformed
+	case errors.Is(err, errUnknownKid):
+		return model.InvalidReasonUnknownKid
+	case errors.Is(err, errAlgMismatch), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return model.InvalidReasonAlgMismatch
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return model.InvalidReasonBadSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return model.InvalidReasonExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return model.InvalidReasonNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return model.InvalidReasonInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return model.InvalidReasonInvalidAudience
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return model.InvalidReasonMissingClaim
+	case errors.Is(err, errTokenTooOld):
+		return model.InvalidReasonTooOld
+	case errors.Is(err, errRevoked):
+		return model.InvalidReasonRevoked
+	case errors.Is(err, errInvalidType):
+		return model.InvalidReasonInvalidType
+	case errors.Is(err, errUnsupportedCrit):
+		return model.InvalidReasonUnsupportedCrit
+	}
+
+	return model.InvalidReasonInvalid
+}
+
+// nextRefreshToken generates the next token of the family: "<family id>.<generation>.<secret>",
+// only its hash is kept
+// nextRefreshToken issues the next generation of the family: "<family id>.<gen>.<random>.<mac>",
+// the mac lets recognize rotated out tokens without storing their hashes
+func nextRefreshToken(family *model.RefreshFamily, now time.Time) (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	family.Gen++
+
+	token := family.Id + "." + strconv.FormatInt(family.Gen, 10) + "." + hex.EncodeToString(b)
+	token += "." + refreshTokenMac(family.MacKey, token)
+
+	tokenHash := sha256.Sum256([]byte(token))
+
+	family.TokenHash = hex.EncodeToString(tokenHash[:])
+	family.ExpiresAt = now.Add(family.Ttl)
+
+	return token, nil
+}
+
+// refreshTokenMac - hex of truncated hmac-sha256 of the token without the mac part
+func refreshTokenMac(macKey, tokenBody string) string {
+	mac := hmac.New(sha256.New, []byte(macKey))
+	mac.Write([]byte(tokenBody))
+
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// refreshTokenIssued checks the mac of a token of the family
+func refreshTokenIssued(family *model.RefreshFamily, token string) bool {
+	if family.MacKey == "" {
+		return false
+	}
+
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return false
+	}
+
+	return hmac.Equal([]byte(token[i+1:]), []byte(refreshTokenMac(family.MacKey, token[:i])))
+}
+
+func parseRefreshToken(token string) (string, int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] == "" || parts[2] == "" || parts[3] == "" {
+		return "", 0, false
+	}
+
+	gen, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || gen <= 0 {
+		return "", 0, false
+	}
+
+	return parts[0], gen, true
+}
+
+func newMacKey() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func newJti() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
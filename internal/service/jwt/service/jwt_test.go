@@ -0,0 +1,924 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	clientModel "github.com/rendau/jwts/internal/service/client/model"
+	clientServiceP "github.com/rendau/jwts/internal/service/client/service"
+	e_jwk "github.com/rendau/jwts/internal/service/jwk/e-jwk"
+	jwkModel "github.com/rendau/jwts/internal/service/jwk/model"
+	jwkServiceP "github.com/rendau/jwts/internal/service/jwk/service"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+	refreshStoreMem "github.com/rendau/jwts/internal/service/jwt/refresh-store/mem"
+	revokeStoreMem "github.com/rendau/jwts/internal/service/jwt/revoke-store/mem"
+	jwtsModel "github.com/rendau/jwts/internal/service/jwts/model"
+	jwtsServiceP "github.com/rendau/jwts/internal/service/jwts/service"
+)
+
+func TestCreateValidate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  any
+		alg  string
+	}{
+		{"rsa", rsaKey, "RS256"},
+		{"p256", genEcKey(t, elliptic.P256()), "ES256"},
+		{"p384", genEcKey(t, elliptic.P384()), "ES384"},
+		{"p521", genEcKey(t, elliptic.P521()), "ES512"},
+		{"ed25519", edKey, "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := parseKey(t, "kid-"+tt.name, tt.key)
+			key.Active = true
+			require.Equal(t, tt.alg, key.Alg)
+
+			jwtsService := jwtsServiceP.New()
+			require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{key}))
+
+			srv := New(Options{
+				JwtsService: jwtsService,
+				Issuers:     []string{"issuer"},
+			})
+
+			createRep, err := srv.Create(&model.JwtCreateReq{
+				Sub:        "user-1",
+				ExpSeconds: 60,
+				Payload:    map[string]any{"role": "admin"},
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, createRep.Token)
+
+			token, _, err := jwt.NewParser().ParseUnverified(createRep.Token, jwt.MapClaims{})
+			require.NoError(t, err)
+			require.Equal(t, tt.alg, token.Method.Alg())
+			require.Equal(t, "kid-"+tt.name, token.Header["kid"])
+
+			validateRep, err := srv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+			require.NoError(t, err)
+			require.True(t, validateRep.Valid)
+			require.Equal(t, "user-1", validateRep.Claims["sub"])
+			require.Equal(t, "admin", validateRep.Claims["role"])
+		})
+	}
+}
+
+func TestValidateRejectsForeignAlg(t *testing.T) {
+	srv, _ := newTestService(t, Options{})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "x"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: token})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+	require.Equal(t, model.InvalidReasonAlgMismatch, validateRep.Reason)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: "garbage"})
+	require.NoError(t, err)
+	require.Equal(t, model.InvalidReasonMalformed, validateRep.Reason)
+}
+
+func TestValidatePolicy(t *testing.T) {
+	srv, key := newTestService(t, Options{
+		DefaultPolicy: model.ValidatePolicy{
+			Issuers: []string{"issuer-a", "issuer-b"},
+		},
+	})
+
+	now := time.Now()
+
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims).SignedString(key.PrivateKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	for _, tc := range []struct {
+		name   string
+		claims jwt.MapClaims
+		policy model.ValidatePolicy
+		reason model.InvalidReason
+	}{
+		{"default issuer", jwt.MapClaims{"iss": "issuer-b"}, model.ValidatePolicy{}, ""},
+		{"foreign issuer", jwt.MapClaims{"iss": "issuer-c"}, model.ValidatePolicy{}, model.InvalidReasonInvalidIssuer},
+		{"request issuer", jwt.MapClaims{"iss": "issuer-c"}, model.ValidatePolicy{Issuers: []string{"issuer-c"}}, ""},
+		{"audience", jwt.MapClaims{"iss": "issuer-a", "aud": []string{"x", "api"}}, model.ValidatePolicy{Audiences: []string{"api"}}, ""},
+		{"foreign audience", jwt.MapClaims{"iss": "issuer-a", "aud": "x"}, model.ValidatePolicy{Audiences: []string{"api"}}, model.InvalidReasonInvalidAudience},
+		{"no audience", jwt.MapClaims{"iss": "issuer-a"}, model.ValidatePolicy{Audiences: []string{"api"}}, model.InvalidReasonMissingClaim},
+		{"required claim", jwt.MapClaims{"iss": "issuer-a", "sid": "1"}, model.ValidatePolicy{RequiredClaims: []string{"sid"}}, ""},
+		{"missing claim", jwt.MapClaims{"iss": "issuer-a"}, model.ValidatePolicy{RequiredClaims: []string{"sid"}}, model.InvalidReasonMissingClaim},
+		{"nbf", jwt.MapClaims{"iss": "issuer-a", "nbf": now.Add(time.Minute).Unix()}, model.ValidatePolicy{}, model.InvalidReasonNotYetValid},
+		{"nbf leeway", jwt.MapClaims{"iss": "issuer-a", "nbf": now.Add(time.Minute).Unix()}, model.ValidatePolicy{Leeway: 2 * time.Minute}, ""},
+		{"exp leeway", jwt.MapClaims{"iss": "issuer-a", "exp": now.Add(-time.Minute).Unix()}, model.ValidatePolicy{Leeway: 2 * time.Minute}, ""},
+		{"max age", jwt.MapClaims{"iss": "issuer-a", "iat": now.Add(-time.Minute).Unix()}, model.ValidatePolicy{MaxAge: 2 * time.Minute}, ""},
+		{"too old", jwt.MapClaims{"iss": "issuer-a", "iat": now.Add(-time.Hour).Unix()}, model.ValidatePolicy{MaxAge: 2 * time.Minute}, model.InvalidReasonTooOld},
+		{"expired", jwt.MapClaims{"iss": "issuer-a", "exp": now.Add(-time.Minute).Unix()}, model.ValidatePolicy{}, model.InvalidReasonExpired},
+		{"max age without iat", jwt.MapClaims{"iss": "issuer-a"}, model.ValidatePolicy{MaxAge: 2 * time.Minute}, model.InvalidReasonMissingClaim},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			validateRep, err := srv.Validate(&model.JwtValidateReq{Token: sign(tc.claims), ValidatePolicy: tc.policy})
+			require.NoError(t, err)
+			require.Equal(t, tc.reason == "", validateRep.Valid)
+			require.Equal(t, tc.reason, validateRep.Reason)
+		})
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	strict := true
+	notStrict := false
+
+	srv, key := newTestService(t, Options{
+		DefaultPolicy: model.ValidatePolicy{Strict: &strict},
+	})
+
+	token, err := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), jwt.MapClaims{
+		"sub": "user-1",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}).SignedString(key.PrivateKey)
+	require.NoError(t, err)
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: token})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+	require.Nil(t, validateRep.Claims)
+	require.Nil(t, validateRep.UnverifiedClaims)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: token, IncludeUnverifiedClaims: true})
+	require.NoError(t, err)
+	require.Nil(t, validateRep.Claims)
+	require.Equal(t, "user-1", validateRep.UnverifiedClaims["sub"])
+
+	// per-request override
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: token, ValidatePolicy: model.ValidatePolicy{Strict: &notStrict}})
+	require.NoError(t, err)
+	require.Equal(t, "user-1", validateRep.Claims["sub"])
+}
+
+func TestRevoke(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		RevokeStore: revokeStoreMem.New(),
+	})
+
+	create := func() (string, string) {
+		rep, err := srv.Create(&model.JwtCreateReq{Sub: "user-1", ExpSeconds: 60})
+		require.NoError(t, err)
+
+		validateRep, err := srv.Validate(&model.JwtValidateReq{Token: rep.Token})
+		require.NoError(t, err)
+		require.True(t, validateRep.Valid)
+
+		return rep.Token, validateRep.Claims["jti"].(string)
+	}
+
+	token1, jti1 := create()
+	token2, jti2 := create()
+	require.NotEqual(t, jti1, jti2)
+
+	// by jti
+	require.NoError(t, srv.Revoke(&model.JwtRevokeReq{Jti: jti1, ExpiresAt: time.Now().Add(time.Minute)}))
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: token1})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+	require.Equal(t, model.InvalidReasonRevoked, validateRep.Reason)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: token2})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid)
+
+	// by token
+	require.NoError(t, srv.Revoke(&model.JwtRevokeReq{Token: token2}))
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: token2})
+	require.NoError(t, err)
+	require.Equal(t, model.InvalidReasonRevoked, validateRep.Reason)
+
+	require.Error(t, srv.Revoke(&model.JwtRevokeReq{Token: "garbage"}))
+	require.Error(t, srv.Revoke(&model.JwtRevokeReq{}))
+
+	// a bare jti needs the expiration, the entry would be kept forever
+	requireErrFull(t, srv.Revoke(&model.JwtRevokeReq{Jti: jti2}), errs.InvalidRequest)
+}
+
+func TestRevokeSubject(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		RevokeStore: revokeStoreMem.New(),
+	})
+
+	validate := func(token string) *model.JwtValidateRep {
+		validateRep, err := srv.Validate(&model.JwtValidateReq{Token: token})
+		require.NoError(t, err)
+		return validateRep
+	}
+
+	oldRep, err := srv.Create(&model.JwtCreateReq{Sub: "user-1"})
+	require.NoError(t, err)
+	otherRep, err := srv.Create(&model.JwtCreateReq{Sub: "user-2"})
+	require.NoError(t, err)
+
+	require.NoError(t, srv.RevokeSubject(&model.SubjectCutoff{Sub: "user-1"}))
+
+	require.Equal(t, model.InvalidReasonRevoked, validate(oldRep.Token).Reason)
+	require.True(t, validate(otherRep.Token).Valid)
+
+	// tokens issued right after the cutoff are valid
+	newRep, err := srv.Create(&model.JwtCreateReq{Sub: "user-1"})
+	require.NoError(t, err)
+	require.True(t, validate(newRep.Token).Valid)
+
+	cutoffs, err := srv.ListSubjectCutoffs()
+	require.NoError(t, err)
+	require.Len(t, cutoffs, 1)
+	require.Equal(t, "user-1", cutoffs[0].Sub)
+
+	require.NoError(t, srv.ClearSubjectCutoff("user-1"))
+	require.True(t, validate(oldRep.Token).Valid)
+
+	requireErrFull(t, srv.RevokeSubject(&model.SubjectCutoff{}), errs.InvalidRequest)
+	requireErrFull(t, srv.RevokeSubject(&model.SubjectCutoff{Sub: "user-1", Before: time.Now().Add(time.Hour)}), errs.InvalidRequest)
+}
+
+func TestRefresh(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		RevokeStore:  revokeStoreMem.New(),
+		RefreshStore: refreshStoreMem.New(),
+		RefreshTtl:   time.Hour,
+	})
+
+	createRep, err := srv.Create(&model.JwtCreateReq{
+		Sub:         "user-1",
+		ExpSeconds:  60,
+		Payload:     map[string]any{"role": "admin"},
+		WithRefresh: true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, createRep.RefreshToken)
+
+	refreshRep, err := srv.Refresh(&model.JwtRefreshReq{RefreshToken: createRep.RefreshToken})
+	require.NoError(t, err)
+	require.NotEqual(t, createRep.RefreshToken, refreshRep.RefreshToken)
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: refreshRep.Token})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid)
+	require.Equal(t, "user-1", validateRep.Claims["sub"])
+	require.Equal(t, "admin", validateRep.Claims["role"])
+
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: "garbage"})
+	requireErrFull(t, err, errs.InvalidGrant)
+
+	// a forged token of an earlier generation does not revoke the family
+	familyId, _, _ := strings.Cut(createRep.RefreshToken, ".")
+
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: familyId + ".1.anything.anything"})
+	requireErrFull(t, err, errs.InvalidGrant)
+
+	refreshRep, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: refreshRep.RefreshToken})
+	require.NoError(t, err)
+
+	// reuse of the rotated out token revokes the family
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: createRep.RefreshToken})
+	requireErrFull(t, err, errs.InvalidGrant)
+
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: refreshRep.RefreshToken})
+	requireErrFull(t, err, errs.InvalidGrant)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: refreshRep.Token})
+	require.NoError(t, err)
+	require.Equal(t, model.InvalidReasonRevoked, validateRep.Reason)
+
+	// subject revocation
+	createRep, err = srv.Create(&model.JwtCreateReq{Sub: "user-2", WithRefresh: true})
+	require.NoError(t, err)
+
+	// the cutoff has second precision
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	require.NoError(t, srv.RevokeSubject(&model.SubjectCutoff{Sub: "user-2"}))
+
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: createRep.RefreshToken})
+	requireErrFull(t, err, errs.InvalidGrant)
+}
+
+func TestExchange(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		ClientService: clientServiceP.New([]*clientModel.Client{
+			{
+				Id:         "orders-client",
+				GrantTypes: []string{clientModel.GrantTypeTokenExchange},
+				Scopes:     []string{"read", "write"},
+				Audiences:  []string{"billing"},
+				TokenTtl:   time.Hour,
+			},
+			{
+				Id:         "reports",
+				GrantTypes: []string{clientModel.GrantTypeClientCredentials},
+				Audiences:  []string{"billing"},
+			},
+			{
+				Id:         "no-audience",
+				GrantTypes: []string{clientModel.GrantTypeTokenExchange},
+			},
+		}, time.Hour),
+		Issuers: []string{"issuer"},
+	})
+
+	subjectRep, err := srv.Create(&model.JwtCreateReq{
+		Sub:        "user-1",
+		ExpSeconds: 60,
+		Payload:    map[string]any{"role": "admin", "scope": "read write", "act": map[string]any{"sub": "gateway"}},
+	})
+	require.NoError(t, err)
+
+	actorRep, err := srv.Create(&model.JwtCreateReq{Sub: "orders", ExpSeconds: 60})
+	require.NoError(t, err)
+
+	exchangeRep, err := srv.Exchange(&model.JwtExchangeReq{
+		Caller:       clientCaller("orders-client"),
+		SubjectToken: subjectRep.Token,
+		ActorToken:   actorRep.Token,
+		Scopes:       []string{"read"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"read"}, exchangeRep.Scopes)
+	require.LessOrEqual(t, exchangeRep.ExpSeconds, int64(60))
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{
+		Token:          exchangeRep.Token,
+		ValidatePolicy: model.ValidatePolicy{Audiences: []string{"billing"}},
+	})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid)
+	require.Equal(t, "user-1", validateRep.Claims["sub"])
+	require.Equal(t, "admin", validateRep.Claims["role"])
+	require.Equal(t, "read", validateRep.Claims["scope"])
+	require.Equal(t, "orders-client", validateRep.Claims["client_id"])
+	require.Equal(t, map[string]any{"sub": "orders", "act": map[string]any{"sub": "gateway"}}, validateRep.Claims["act"])
+
+	// without actor token the client is the acting party
+	exchangeRep, err = srv.Exchange(&model.JwtExchangeReq{
+		Caller:       clientCaller("orders-client"),
+		SubjectToken: subjectRep.Token,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"read", "write"}, exchangeRep.Scopes)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: exchangeRep.Token})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"client_id": "orders-client", "act": map[string]any{"sub": "gateway"}}, validateRep.Claims["act"])
+
+	// the lifetime of a subject token without exp is capped by the client token ttl
+	noExpRep, err := srv.Create(&model.JwtCreateReq{Sub: "user-1"})
+	require.NoError(t, err)
+
+	exchangeRep, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("orders-client"), SubjectToken: noExpRep.Token})
+	require.NoError(t, err)
+	require.Equal(t, int64(3600), exchangeRep.ExpSeconds)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: exchangeRep.Token})
+	require.NoError(t, err)
+	require.Contains(t, validateRep.Claims, "exp")
+
+	_, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("orders-client"), SubjectToken: "garbage"})
+	requireErrFull(t, err, errs.InvalidRequest)
+
+	_, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("orders-client"), SubjectToken: subjectRep.Token, Audiences: []string{"other"}})
+	requireErrFull(t, err, errs.InvalidTarget)
+
+	_, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("orders-client"), SubjectToken: subjectRep.Token, Scopes: []string{"admin"}})
+	requireErrFull(t, err, errs.InvalidScope)
+
+	_, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("no-audience"), SubjectToken: subjectRep.Token})
+	requireErrFull(t, err, errs.InvalidTarget)
+
+	for _, caller := range []*callerModel.Caller{
+		nil,
+		clientCaller("unknown"),
+		clientCaller("reports"),
+		{Id: "orders-client", Method: callerModel.MethodApiKey},
+		{Id: "orders-client", Method: callerModel.MethodCertificate},
+	} {
+		_, err = srv.Exchange(&model.JwtExchangeReq{Caller: caller, SubjectToken: subjectRep.Token})
+		requireErrFull(t, err, errs.UnauthorizedClient)
+	}
+}
+
+func TestMintPolicy(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		RefreshStore: refreshStoreMem.New(),
+		RefreshTtl:   time.Hour,
+		ClientService: clientServiceP.New([]*clientModel.Client{{
+			Id:         "backend",
+			GrantTypes: []string{clientModel.GrantTypeTokenExchange},
+			Audiences:  []string{"api"},
+		}}, time.Hour),
+		MintPolicies: []*model.MintPolicy{
+			{
+				CallerId:      "backend",
+				CallerMethod:  callerModel.MethodClient,
+				SubPatterns:   []string{"user-*"},
+				Audiences:     []string{"api"},
+				MaxExpSeconds: 3600,
+				AllowedClaims: []string{"role", "act", "client_id"},
+				ForcedClaims:  map[string]any{"tenant": "acme"},
+			},
+			{
+				CallerId:        "*",
+				ForbiddenClaims: []string{"role"},
+			},
+		},
+	})
+
+	createRep, err := srv.Create(&model.JwtCreateReq{
+		Caller:     clientCaller("backend"),
+		Sub:        "user-1",
+		ExpSeconds: 60,
+		Aud:        []string{"api"},
+		Payload:    map[string]any{"role": "admin", "tenant": "other"},
+	})
+	require.NoError(t, err)
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid)
+	require.Equal(t, "acme", validateRep.Claims["tenant"])
+
+	_, err = srv.Create(&model.JwtCreateReq{
+		Caller:     clientCaller("backend"),
+		Sub:        "admin",
+		ExpSeconds: 7200,
+		Aud:        []string{"billing"},
+		Payload:    map[string]any{"email": "a@b.c"},
+	})
+
+	var errFull errs.ErrFull
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, errs.PermissionDenied, errFull.Err)
+	require.Equal(t, map[string]string{
+		"sub":         "not allowed",
+		"exp_seconds": "must be between 1 and 3600",
+		"aud":         "not allowed",
+		"email":       "not allowed",
+	}, errFull.Fields)
+
+	_, err = srv.Create(&model.JwtCreateReq{Caller: clientCaller("backend"), Sub: "user-1", ExpSeconds: 60})
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, map[string]string{"aud": "required"}, errFull.Fields)
+
+	// default policy for other callers, including ones of other authentication methods with the same id
+	for _, caller := range []*callerModel.Caller{
+		{Id: "other", Method: callerModel.MethodApiKey},
+		{Id: "backend", Method: callerModel.MethodApiKey},
+	} {
+		_, err = srv.Create(&model.JwtCreateReq{Caller: caller, Sub: "user-1", Payload: map[string]any{"role": "admin"}})
+		requireErrFull(t, err, errs.PermissionDenied)
+	}
+
+	_, err = srv.Create(&model.JwtCreateReq{Sub: "x", Payload: map[string]any{"nbf": 1}})
+	requireErrFull(t, err, errs.InvalidRequest)
+
+	// exchange is minted under the policy of the calling client
+	adminRep, err := srv.Create(&model.JwtCreateReq{Sub: "admin", ExpSeconds: 60})
+	require.NoError(t, err)
+
+	_, err = srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("backend"), SubjectToken: adminRep.Token})
+	requireErrFull(t, err, errs.PermissionDenied)
+
+	exchangeRep, err := srv.Exchange(&model.JwtExchangeReq{Caller: clientCaller("backend"), SubjectToken: createRep.Token})
+	require.NoError(t, err)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: exchangeRep.Token})
+	require.NoError(t, err)
+	require.Equal(t, "acme", validateRep.Claims["tenant"])
+
+	// refresh is checked against the current policy of the creator
+	createRep, err = srv.Create(&model.JwtCreateReq{
+		Caller:      clientCaller("backend"),
+		Sub:         "user-1",
+		ExpSeconds:  60,
+		Aud:         []string{"api"},
+		WithRefresh: true,
+	})
+	require.NoError(t, err)
+
+	refreshRep, err := srv.Refresh(&model.JwtRefreshReq{RefreshToken: createRep.RefreshToken})
+	require.NoError(t, err)
+
+	srv.mintPolicies[0].SubPatterns = []string{"svc-*"}
+
+	_, err = srv.Refresh(&model.JwtRefreshReq{RefreshToken: refreshRep.RefreshToken})
+	requireErrFull(t, err, errs.PermissionDenied)
+}
+
+func TestRegisteredClaims(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		Issuers: []string{"jwts", "other"},
+		MintPolicies: []*model.MintPolicy{
+			{CallerId: "legacy", CallerMethod: callerModel.MethodApiKey, PayloadClaims: []string{"iss", "jti"}},
+		},
+	})
+
+	legacyCaller := &callerModel.Caller{Id: "legacy", Method: callerModel.MethodApiKey}
+
+	now := time.Now()
+
+	createRep, err := srv.Create(&model.JwtCreateReq{
+		Caller:     legacyCaller,
+		Sub:        "user-1",
+		ExpSeconds: 60,
+		Iss:        "other",
+		Aud:        []string{"api"},
+		Nbf:        now.Add(-time.Minute),
+		Iat:        now.Add(-time.Minute),
+		Jti:        "id-1",
+		Payload:    map[string]any{"sub": "user-1", "role": "admin"},
+	})
+	require.NoError(t, err)
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid, validateRep.Detail)
+	require.Equal(t, "other", validateRep.Claims["iss"])
+	require.Equal(t, []any{"api"}, validateRep.Claims["aud"])
+	require.Equal(t, float64(now.Add(-time.Minute).Unix()), validateRep.Claims["nbf"])
+	require.Equal(t, float64(now.Add(-time.Minute).Unix()), validateRep.Claims["iat"])
+	require.Equal(t, "api_key:legacy:id-1", validateRep.Claims["jti"])
+	require.Equal(t, "admin", validateRep.Claims["role"])
+
+	_, err = srv.Create(&model.JwtCreateReq{
+		Caller:     &callerModel.Caller{Id: "backend", Method: callerModel.MethodApiKey},
+		Sub:        "user-1",
+		ExpSeconds: 60,
+		Aud:        []string{""},
+		Nbf:        now.Add(time.Hour),
+		Iat:        now.Add(time.Hour),
+		Jti:        strings.Repeat("x", 256),
+		Payload:    map[string]any{"iss": "fake", "sub": "admin", "exp": 1},
+	})
+
+	var errFull errs.ErrFull
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, errs.InvalidRequest, errFull.Err)
+	require.Equal(t, map[string]string{
+		"aud": "must not contain empty values",
+		"nbf": "must be before exp",
+		"iat": "must not be in the future",
+		"jti": "must be at most 255 characters",
+		"iss": "registered claim, use the typed field",
+		"sub": "registered claim, use the typed field",
+		"exp": "registered claim, use the typed field",
+	}, errFull.Fields)
+
+	// jti without caller namespace
+	_, err = srv.Create(&model.JwtCreateReq{Sub: "user-1", Jti: "id-1"})
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, map[string]string{"jti": "requires an authenticated caller"}, errFull.Fields)
+
+	// explicitly allowed payload override
+	createRep, err = srv.Create(&model.JwtCreateReq{Caller: legacyCaller, Sub: "x", Payload: map[string]any{"iss": "other"}})
+	require.NoError(t, err)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+	require.NoError(t, err)
+	require.Equal(t, "other", validateRep.Claims["iss"])
+
+	_, err = srv.Create(&model.JwtCreateReq{Caller: legacyCaller, Sub: "x", Payload: map[string]any{"iss": "legacy"}})
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, map[string]string{"iss": "must be one of the configured issuers"}, errFull.Fields)
+
+	// iat and jti are always set by the service
+	_, err = srv.Create(&model.JwtCreateReq{Caller: legacyCaller, Sub: "x", Payload: map[string]any{"jti": "x"}})
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, map[string]string{"jti": "registered claim, use the typed field"}, errFull.Fields)
+
+	// absolute exp, relative nbf, default issuer
+	createRep, err = srv.Create(&model.JwtCreateReq{Sub: "x", Exp: now.Add(time.Hour), NbfSeconds: -30})
+	require.NoError(t, err)
+
+	validateRep, err = srv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+	require.NoError(t, err)
+	require.True(t, validateRep.Valid, validateRep.Detail)
+	require.Equal(t, "jwts", validateRep.Claims["iss"])
+	require.Equal(t, float64(now.Add(time.Hour).Unix()), validateRep.Claims["exp"])
+	require.InDelta(t, float64(now.Unix()-30), validateRep.Claims["nbf"], 1)
+
+	_, err = srv.Create(&model.JwtCreateReq{
+		Sub:        "x",
+		Iss:        "unknown",
+		Exp:        now.Add(time.Hour),
+		ExpSeconds: 60,
+		Nbf:        now,
+		NbfSeconds: 10,
+	})
+
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, map[string]string{
+		"iss": "must be one of the configured issuers",
+		"exp": "exclusive with exp_seconds",
+		"nbf": "exclusive with nbf_seconds",
+	}, errFull.Fields)
+
+	_, err = srv.Create(&model.JwtCreateReq{Sub: "x", Exp: now.Add(-time.Second)})
+	requireErrFull(t, err, errs.InvalidRequest)
+}
+
+func TestHeader(t *testing.T) {
+	srv, _ := newTestService(t, Options{
+		RefreshStore: refreshStoreMem.New(),
+		RefreshTtl:   time.Hour,
+		ExtraHeaders: []string{"x5t#S256", "urn:ext"},
+		CritHeaders:  []string{"urn:ext"},
+	})
+
+	createRep, err := srv.Create(&model.JwtCreateReq{
+		Sub:         "user-1",
+		ExpSeconds:  60,
+		Typ:         "at+jwt",
+		Crit:        []string{"urn:ext"},
+		Header:      map[string]any{"x5t#S256": "abc", "urn:ext": true},
+		WithRefresh: true,
+	})
+	require.NoError(t, err)
+
+	refreshRep, err := srv.Refresh(&model.JwtRefreshReq{RefreshToken: createRep.RefreshToken})
+	require.NoError(t, err)
+
+	for _, token := range []string{createRep.Token, refreshRep.Token} {
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		require.NoError(t, err)
+		require.Equal(t, "at+jwt", parsed.Header["typ"])
+		require.Equal(t, "abc", parsed.Header["x5t#S256"])
+		require.Equal(t, []any{"urn:ext"}, parsed.Header["crit"])
+		require.Equal(t, "k1", parsed.Header["kid"])
+
+		validateRep, err := srv.Validate(&model.JwtValidateReq{
+			Token:          token,
+			ValidatePolicy: model.ValidatePolicy{Typ: "application/AT+JWT"},
+		})
+		require.NoError(t, err)
+		require.True(t, validateRep.Valid, validateRep.Detail)
+	}
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{
+		Token:          createRep.Token,
+		ValidatePolicy: model.ValidatePolicy{Typ: "JWT"},
+	})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+	require.Equal(t, model.InvalidReasonInvalidType, validateRep.Reason)
+
+	// the extension is not understood by another instance
+	otherSrv := New(Options{JwtsService: srv.jwtsService})
+
+	validateRep, err = otherSrv.Validate(&model.JwtValidateReq{Token: createRep.Token})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+	require.Equal(t, model.InvalidReasonUnsupportedCrit, validateRep.Reason)
+
+	_, err = srv.Create(&model.JwtCreateReq{
+		Sub:    "user-1",
+		Crit:   []string{"urn:ext"},
+		Header: map[string]any{"alg": "none", "jku": "https://example.com"},
+	})
+
+	var errFull errs.ErrFull
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, errs.InvalidRequest, errFull.Err)
+	require.Equal(t, map[string]string{
+		"header.alg": "reserved header parameter",
+		"header.jku": "not allowed",
+		"crit":       `extension "urn:ext" is not set in the header`,
+	}, errFull.Fields)
+}
+
+func TestKeyring(t *testing.T) {
+	oldKey := parseKey(t, "old", genEcKey(t, elliptic.P256()))
+	oldKey.Active = true
+
+	jwtsService := jwtsServiceP.New()
+	require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{oldKey}))
+
+	srv := New(Options{
+		JwtsService: jwtsService,
+	})
+
+	oldToken, err := srv.Create(&model.JwtCreateReq{Sub: "user-1", ExpSeconds: 60})
+	require.NoError(t, err)
+
+	// rotate: new key is active, old one is kept for verification only
+	newKey := parseKey(t, "new", genEcKey(t, elliptic.P384()))
+	newKey.Active = true
+	retiredKey := *oldKey
+	retiredKey.Active = false
+	retiredKey.ExpiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{newKey, &retiredKey}))
+
+	newToken, err := srv.Create(&model.JwtCreateReq{Sub: "user-1", ExpSeconds: 60})
+	require.NoError(t, err)
+
+	token, _, err := jwt.NewParser().ParseUnverified(newToken.Token, jwt.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, "new", token.Header["kid"])
+
+	for _, tkn := range []string{oldToken.Token, newToken.Token} {
+		validateRep, err := srv.Validate(&model.JwtValidateReq{Token: tkn})
+		require.NoError(t, err)
+		require.True(t, validateRep.Valid)
+	}
+
+	// retired key expired
+	retiredKey.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{newKey, &retiredKey}))
+
+	validateRep, err := srv.Validate(&model.JwtValidateReq{Token: oldToken.Token})
+	require.NoError(t, err)
+	require.False(t, validateRep.Valid)
+
+	// two active keys
+	require.Error(t, jwtsService.SetKeys([]*jwtsModel.Key{newKey, oldKey}))
+}
+
+type fakeEJwk struct {
+	issuer string
+	jwks   *jwkModel.JwkSet
+}
+
+func (f *fakeEJwk) Name() string {
+	return "fake"
+}
+
+func (f *fakeEJwk) Issuer() string {
+	return f.issuer
+}
+
+func (f *fakeEJwk) FetchJwks(ctx context.Context) (*jwkModel.JwkSet, time.Duration, error) {
+	return f.jwks, 0, nil
+}
+
+func TestValidateExternal(t *testing.T) {
+	localKey := parseKey(t, "local", genEcKey(t, elliptic.P256()))
+	localKey.Active = true
+
+	jwtsService := jwtsServiceP.New()
+	require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{localKey}))
+
+	// external keys are published by another jwts instance
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	eKeys := []*jwtsModel.Key{
+		parseKey(t, "ext-rsa", rsaKey),
+		parseKey(t, "ext-ec", genEcKey(t, elliptic.P521())),
+		parseKey(t, "ext-ed", edKey),
+	}
+
+	eJwtsService := jwtsServiceP.New()
+	require.NoError(t, eJwtsService.SetKeys(eKeys))
+	eJwkService := jwkServiceP.New(eJwtsService, nil)
+	require.NoError(t, eJwkService.UpdateKeys())
+
+	jwkService := jwkServiceP.New(jwtsService, []e_jwk.EJwkServiceI{
+		&fakeEJwk{issuer: "https://idp.example.com", jwks: eJwkService.GetSet()},
+	})
+	require.NoError(t, jwkService.UpdateKeys())
+
+	_, err = jwkService.RefreshExternalKeys(context.Background())
+	require.NoError(t, err)
+
+	srv := New(Options{
+		JwtsService: jwtsService,
+		JwkService:  jwkService,
+	})
+
+	for _, eKey := range eKeys {
+		t.Run(eKey.Kid, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.GetSigningMethod(eKey.Alg), jwt.MapClaims{"sub": "ext-user", "iss": "https://idp.example.com"})
+			token.Header["kid"] = eKey.Kid
+
+			tokenStr, err := token.SignedString(eKey.PrivateKey)
+			require.NoError(t, err)
+
+			validateRep, err := srv.Validate(&model.JwtValidateReq{Token: tokenStr})
+			require.NoError(t, err)
+			require.True(t, validateRep.Valid)
+			require.Equal(t, "ext-user", validateRep.Claims["sub"])
+
+			// issuer of another upstream
+			token.Claims.(jwt.MapClaims)["iss"] = "https://other.example.com"
+			tokenStr, err = token.SignedString(eKey.PrivateKey)
+			require.NoError(t, err)
+
+			validateRep, err = srv.Validate(&model.JwtValidateReq{Token: tokenStr})
+			require.NoError(t, err)
+			require.False(t, validateRep.Valid)
+
+			// unknown kid
+			token.Header["kid"] = "unknown"
+			tokenStr, err = token.SignedString(eKey.PrivateKey)
+			require.NoError(t, err)
+
+			validateRep, err = srv.Validate(&model.JwtValidateReq{Token: tokenStr})
+			require.NoError(t, err)
+			require.False(t, validateRep.Valid)
+			require.Equal(t, model.InvalidReasonUnknownKid, validateRep.Reason)
+		})
+	}
+}
+
+// newTestService creates the service with an active P-256 key "k1", unless opts.JwtsService is set
+func newTestService(t *testing.T, opts Options) (*Service, *jwtsModel.Key) {
+	t.Helper()
+
+	var key *jwtsModel.Key
+
+	if opts.JwtsService == nil {
+		key = parseKey(t, "k1", genEcKey(t, elliptic.P256()))
+		key.Active = true
+
+		jwtsService := jwtsServiceP.New()
+		require.NoError(t, jwtsService.SetKeys([]*jwtsModel.Key{key}))
+
+		opts.JwtsService = jwtsService
+	}
+
+	return New(opts), key
+}
+
+func clientCaller(id string) *callerModel.Caller {
+	return &callerModel.Caller{Id: id, Method: callerModel.MethodClient}
+}
+
+func parseKey(t *testing.T, kid string, key any) *jwtsModel.Key {
+	privatePem, publicPem := encodePem(t, key)
+
+	result, err := jwtsServiceP.ParseKey(kid, privatePem, publicPem)
+	require.NoError(t, err)
+
+	return result
+}
+
+func requireErrFull(t *testing.T, err error, expected errs.Err) {
+	t.Helper()
+
+	var errFull errs.ErrFull
+	require.ErrorAs(t, err, &errFull)
+	require.Equal(t, expected, errFull.Err)
+}
+
+func genEcKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func encodePem(t *testing.T, key any) ([]byte, []byte) {
+	var privateBlock *pem.Block
+	var publicKey any
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		privateBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
+		publicKey = &k.PublicKey
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		require.NoError(t, err)
+		privateBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+		publicKey = &k.PublicKey
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		require.NoError(t, err)
+		privateBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		publicKey = k.Public()
+	}
+
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(privateBlock), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/rendau/jwts/internal/errs"
+	callerModel "github.com/rendau/jwts/internal/service/caller/model"
+	"github.com/rendau/jwts/internal/service/jwt/model"
+)
+
+// callerMethods - authentication methods a policy can be bound to
+var callerMethods = []string{callerModel.MethodApiKey, callerModel.MethodCertificate, callerModel.MethodClient}
+
+type mintPoliciesFile struct {
+	Policies []mintPoliciesFileItem `json:"policies"`
+}
+
+type mintPoliciesFileItem struct {
+	CallerId        string         `json:"caller_id"`
+	CallerMethod    string         `json:"caller_method"`
+	SubPatterns     []string       `json:"sub_patterns"`
+	Audiences       []string       `json:"audiences"`
+	MaxExpSeconds   int64          `json:"max_exp_seconds"`
+	AllowedClaims   []string       `json:"allowed_claims"`
+	ForcedClaims    map[string]any `json:"forced_claims"`
+	ForbiddenClaims []string       `json:"forbidden_claims"`
+	PayloadClaims   []string       `json:"payload_claims"`
+}
+
+// LoadMintPolicies reads mint policies file
+func LoadMintPolicies(filePath string) ([]*model.MintPolicy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pf mintPoliciesFile
+	if err = json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("fail to parse mint policies file: %w", err)
+	}
+
+	result := make([]*model.MintPolicy, 0, len(pf.Policies))
+
+	for _, item := range pf.Policies {
+		if item.CallerId == "" {
+			return nil, fmt.Errorf("mint policies file: caller_id is required")
+		}
+
+		if item.CallerId != "*" && !slices.Contains(callerMethods, item.CallerMethod) {
+			return nil, fmt.Errorf("mint policy of %q: caller_method must be one of %v", item.CallerId, callerMethods)
+		}
+
+		for _, pattern := range item.SubPatterns {
+			if _, err = path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("mint policy of %q: sub pattern %q: %w", item.CallerId, pattern, err)
+			}
+		}
+
+		for k := range item.ForcedClaims {
+			if slices.Contains(model.RegisteredClaims, k) {
+				return nil, fmt.Errorf("mint policy of %q: registered claim %q can not be forced", item.CallerId, k)
+			}
+		}
+
+		for _, k := range item.PayloadClaims {
+			if !slices.Contains(model.RegisteredClaims, k) {
+				return nil, fmt.Errorf("mint policy of %q: %q is not a registered claim", item.CallerId, k)
+			}
+
+			if slices.Contains(servicePayloadClaims, k) {
+				return nil, fmt.Errorf("mint policy of %q: %q is set by the service, use the typed field", item.CallerId, k)
+			}
+		}
+
+		result = append(result, &model.MintPolicy{
+			CallerId:        item.CallerId,
+			CallerMethod:    item.CallerMethod,
+			SubPatterns:     item.SubPatterns,
+			Audiences:       item.Audiences,
+			MaxExpSeconds:   item.MaxExpSeconds,
+			AllowedClaims:   item.AllowedClaims,
+			ForcedClaims:    item.ForcedClaims,
+			ForbiddenClaims: item.ForbiddenClaims,
+			PayloadClaims:   item.PayloadClaims,
+		})
+	}
+
+	return result, nil
+}
+
+// mintPolicy returns the policy of the caller (id and authentication method), the "*" policy for others, nil if unrestricted
+func (s *Service) mintPolicy(caller *callerModel.Caller) *model.MintPolicy {
+	var result *model.MintPolicy
+
+	for _, p := range s.mintPolicies {
+		if caller != nil && p.CallerId == caller.Id && p.CallerMethod == caller.Method {
+			return p
+		}
+
+		if p.CallerId == "*" {
+			result = p
+		}
+	}
+
+	return result
+}
+
+// applyMintPolicy checks the create request against the caller policy,
+// returns the payload with forced claims
+func applyMintPolicy(policy *model.MintPolicy, obj *model.JwtCreateReq) (map[string]any, error) {
+	fields := map[string]string{}
+
+	if len(policy.SubPatterns) > 0 && !slices.ContainsFunc(policy.SubPatterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, obj.Sub)
+		return matched
+	}) {
+		fields["sub"] = "not allowed"
+	}
+
+	if policy.MaxExpSeconds > 0 && (obj.ExpSeconds <= 0 || obj.ExpSeconds > policy.MaxExpSeconds) {
+		fields["exp_seconds"] = fmt.Sprintf("must be between 1 and %d", policy.MaxExpSeconds)
+	}
+
+	// a token without "aud" is accepted by any audience
+	if len(policy.Audiences) > 0 {
+		if _, ok := obj.Payload["aud"]; len(obj.Aud) == 0 && !ok {
+			fields["aud"] = "required"
+		} else if len(obj.Aud) > 0 && !audiencesAllowed(obj.Aud, policy.Audiences) {
+			fields["aud"] = "not allowed"
+		}
+	}
+
+	for k, v := range obj.Payload {
+		_, forced := policy.ForcedClaims[k]
+
+		switch {
+		case k == "sub", forced: // overwritten
+		case k == "aud":
+			if len(policy.Audiences) > 0 && !audiencesAllowed(v, policy.Audiences) {
+				fields[k] = "not allowed"
+			}
+		case slices.Contains(model.RegisteredClaims, k): // allowed by PayloadClaims
+		case slices.Contains(policy.ForbiddenClaims, k):
+			fields[k] = "forbidden"
+		case len(policy.AllowedClaims) > 0 && !slices.Contains(policy.AllowedClaims, k):
+			fields[k] = "not allowed"
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, errs.ErrFull{
+			Err:    errs.PermissionDenied,
+			Desc:   fmt.Sprintf("token request violates the mint policy of %q", policy.CallerId),
+			Fields: fields,
+		}
+	}
+
+	result := maps.Clone(obj.Payload)
+	if result == nil {
+		result = make(map[string]any, len(policy.ForcedClaims))
+	}
+
+	maps.Copy(result, policy.ForcedClaims)
+
+	return result, nil
+}
+
+// audiencesAllowed checks "aud" claim value: string or list of strings
+func audiencesAllowed(aud any, allowed []string) bool {
+	switch v := aud.(type) {
+	case string:
+		return slices.Contains(allowed, v)
+	case []string:
+		for _, item := range v {
+			if !slices.Contains(allowed, item) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok || !slices.Contains(allowed, str) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"crypto"
+	"time"
+)
+
+type Key struct {
+	Kid         string
+	Alg         string
+	PrivateKey  crypto.PrivateKey
+	PublicKey   crypto.PublicKey
+	Active      bool      // used for signing
+	ActivatedAt time.Time // when the key became active
+	ActivateAt  time.Time // scheduled activation of a pre-published key
+	ExpiresAt   time.Time // zero value - never expires
+	Generated   bool      // created by key rotation
+}
+
+func (k *Key) IsExpired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
@@ -1,51 +1,244 @@
 package service
 
 import (
-	"crypto/rsa"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/rendau/jwts/internal/service/jwts/model"
 )
 
 type Service struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
-	kid        string
+	mu      sync.RWMutex
+	keys    []*model.Key
+	retired map[string]time.Time // expiration of static keys retired by rotation, by kid
 }
 
-func New(kid string) *Service {
-	return &Service{
-		kid: kid,
+func New() *Service {
+	return &Service{}
+}
+
+// SetKeys replaces the whole keyring
+func (s *Service) SetKeys(keys []*model.Key) error {
+	if err := validateKeys(keys); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+
+	return nil
+}
+
+// SetStaticKeys replaces keys which are not created by key rotation.
+// If a rotated key is active, static keys are used for verification only,
+// static keys retired by rotation keep their expiration
+func (s *Service) SetStaticKeys(keys []*model.Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	result := make([]*model.Key, 0, len(s.keys)+len(keys))
+	rotatedActive := false
+
+	for _, k := range s.keys {
+		if k.Generated {
+			result = append(result, k)
+			rotatedActive = rotatedActive || k.Active
+		}
+	}
+
+	for _, k := range keys {
+		if retireAt, ok := s.retired[k.Kid]; ok {
+			if !now.Before(retireAt) {
+				continue
+			}
+
+			key := *k
+			key.Active = false
+			if key.ExpiresAt.IsZero() || retireAt.Before(key.ExpiresAt) {
+				key.ExpiresAt = retireAt
+			}
+			k = &key
+		} else if rotatedActive && k.Active {
+			key := *k
+			key.Active = false
+			k = &key
+		}
+		result = append(result, k)
+	}
+
+	if err := validateKeys(result); err != nil {
+		return err
+	}
+
+	s.keys = result
+
+	return nil
+}
+
+func validateKeys(keys []*model.Key) error {
+	kids := make(map[string]bool, len(keys))
+	activeCount := 0
+
+	for _, key := range keys {
+		if kids[key.Kid] {
+			return fmt.Errorf("duplicate kid %q", key.Kid)
+		}
+		kids[key.Kid] = true
+
+		if key.PublicKey == nil {
+			return fmt.Errorf("key %q: public key is nil", key.Kid)
+		}
+
+		if key.Active {
+			if key.PrivateKey == nil {
+				return fmt.Errorf("key %q: active key must have private key", key.Kid)
+			}
+			activeCount++
+		}
 	}
+
+	if activeCount > 1 {
+		return fmt.Errorf("only one key can be active, got %d", activeCount)
+	}
+
+	return nil
 }
 
-func (s *Service) SetKeys(privateKeyBytes []byte, publicKeyBytes []byte) error {
-	var err error
+// GetActiveKey returns the signing key, nil if there is no active key
+func (s *Service) GetActiveKey() *model.Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
 
-	if len(privateKeyBytes) > 0 {
-		s.privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
-		if err != nil {
-			return err
+	for _, key := range s.keys {
+		if key.Active && !key.IsExpired(now) {
+			return key
 		}
 	}
 
-	if len(publicKeyBytes) > 0 {
-		s.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyBytes)
-		if err != nil {
-			return err
+	return nil
+}
+
+// GetKey returns non-expired key by kid
+func (s *Service) GetKey(kid string) *model.Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	for _, key := range s.keys {
+		if key.Kid == kid && !key.IsExpired(now) {
+			return key
 		}
 	}
 
 	return nil
 }
 
-func (s *Service) GetPrivateKey() *rsa.PrivateKey {
-	return s.privateKey
+// GetKeys returns all non-expired keys
+func (s *Service) GetKeys() []*model.Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	result := make([]*model.Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		if !key.IsExpired(now) {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+// AddKey adds inactive key to the keyring
+func (s *Service) AddKey(key *model.Key) error {
+	if key.Active {
+		return fmt.Errorf("key %q: use ActivateKey to activate key", key.Kid)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if k.Kid == key.Kid {
+			return fmt.Errorf("duplicate kid %q", key.Kid)
+		}
+	}
+
+	s.keys = append(slices.Clone(s.keys), key)
+
+	return nil
 }
 
-func (s *Service) GetPublicKey() *rsa.PublicKey {
-	return s.publicKey
+// ActivateKey makes the key with kid the signing one.
+// Previously active key is kept for verification only until retireAt
+func (s *Service) ActivateKey(kid string, activatedAt, retireAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.keys, func(k *model.Key) bool { return k.Kid == kid })
+	if idx < 0 {
+		return fmt.Errorf("key %q not found", kid)
+	}
+
+	if s.keys[idx].PrivateKey == nil {
+		return fmt.Errorf("key %q: active key must have private key", kid)
+	}
+
+	// keys are shared with readers, so modify copies
+	keys := slices.Clone(s.keys)
+
+	for i, k := range keys {
+		switch {
+		case i == idx:
+			key := *k
+			key.Active = true
+			key.ActivatedAt = activatedAt
+			key.ActivateAt = time.Time{}
+			keys[i] = &key
+		case k.Active:
+			key := *k
+			key.Active = false
+			if key.ExpiresAt.IsZero() {
+				key.ExpiresAt = retireAt
+			}
+			keys[i] = &key
+
+			// reloaded static keys must not come back unretired
+			if !key.Generated {
+				if s.retired == nil {
+					s.retired = map[string]time.Time{}
+				}
+				s.retired[key.Kid] = key.ExpiresAt
+			}
+		}
+	}
+
+	s.keys = keys
+
+	return nil
 }
 
-func (s *Service) GetKid() string {
-	return s.kid
+// RemoveExpiredKeys removes expired keys from the keyring, returns removed count
+func (s *Service) RemoveExpiredKeys() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	keys := slices.DeleteFunc(slices.Clone(s.keys), func(k *model.Key) bool { return k.IsExpired(now) })
+	removed := len(s.keys) - len(keys)
+
+	s.keys = keys
+
+	return removed
 }
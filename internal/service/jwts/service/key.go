@@ -0,0 +1,150 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+// ParseKey parses PEM encoded key pair, any of them can be empty.
+// Public key is derived from the private one if omitted
+func ParseKey(kid string, privateKeyBytes []byte, publicKeyBytes []byte) (*model.Key, error) {
+	var err error
+
+	result := &model.Key{
+		Kid: kid,
+	}
+
+	if len(privateKeyBytes) > 0 {
+		result.PrivateKey, err = parsePrivateKey(privateKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(publicKeyBytes) > 0 {
+		result.PublicKey, err = parsePublicKey(publicKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+	} else if signer, ok := result.PrivateKey.(crypto.Signer); ok {
+		result.PublicKey = signer.Public()
+	}
+
+	if result.PublicKey == nil {
+		return nil, fmt.Errorf("key %q: no key data", kid)
+	}
+
+	result.Alg, err = keyAlg(result.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.PrivateKey != nil {
+		privateAlg, err := keyAlg(result.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if privateAlg != result.Alg {
+			return nil, fmt.Errorf("private key (%s) and public key (%s) algorithms mismatch", privateAlg, result.Alg)
+		}
+	}
+
+	return result, nil
+}
+
+// GenerateKey generates a new inactive key for the given JWS algorithm
+func GenerateKey(kid, alg string) (*model.Key, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch alg {
+	case "RS256":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ES512":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "EdDSA":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate %s key: %w", alg, err)
+	}
+
+	return &model.Key{
+		Kid:        kid,
+		Alg:        alg,
+		PrivateKey: privateKey,
+		PublicKey:  privateKey.Public(),
+	}, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key, must be PEM encoded RSA, EC or Ed25519 key")
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key, must be PEM encoded RSA, EC or Ed25519 key")
+}
+
+// keyAlg returns the JWS algorithm for the given private or public key
+func keyAlg(key any) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		return keyAlg(&k.PublicKey)
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().Name {
+		case "P-256":
+			return "ES256", nil
+		case "P-384":
+			return "ES384", nil
+		case "P-521":
+			return "ES512", nil
+		}
+		return "", fmt.Errorf("unsupported EC curve %s", k.Curve.Params().Name)
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return "EdDSA", nil
+	}
+
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
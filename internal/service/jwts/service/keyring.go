@@ -0,0 +1,147 @@
+package service
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+type keyringFile struct {
+	Keys []keyringFileKey `json:"keys"`
+}
+
+type keyringFileKey struct {
+	Kid         string    `json:"kid"`
+	PrivatePem  string    `json:"private_pem,omitempty"`
+	PublicPem   string    `json:"public_pem,omitempty"`
+	Active      bool      `json:"active,omitempty"`
+	ActivatedAt time.Time `json:"activated_at,omitzero"`
+	ActivateAt  time.Time `json:"activate_at,omitzero"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"`
+}
+
+// LoadKeyring reads keyring json file. Pem paths are relative to the keyring file directory
+func LoadKeyring(path string) ([]*model.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyringFile
+	if err = json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("fail to parse keyring file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+
+	result := make([]*model.Key, 0, len(kf.Keys))
+
+	for _, item := range kf.Keys {
+		var privatePem, publicPem []byte
+
+		if item.PrivatePem != "" {
+			privatePem, err = os.ReadFile(keyringPath(dir, item.PrivatePem))
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", item.Kid, err)
+			}
+		}
+
+		if item.PublicPem != "" {
+			publicPem, err = os.ReadFile(keyringPath(dir, item.PublicPem))
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", item.Kid, err)
+			}
+		}
+
+		key, err := ParseKey(item.Kid, privatePem, publicPem)
+		if err != nil {
+			return nil, err
+		}
+
+		key.Active = item.Active
+		key.ActivatedAt = item.ActivatedAt
+		key.ActivateAt = item.ActivateAt
+		key.ExpiresAt = item.ExpiresAt
+
+		result = append(result, key)
+	}
+
+	return result, nil
+}
+
+// SaveKeyring writes keys into keyring json file, private keys are written next to it as <kid>.pem
+func SaveKeyring(path string, keys []*model.Key) error {
+	dir := filepath.Dir(path)
+
+	kf := keyringFile{
+		Keys: make([]keyringFileKey, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		item := keyringFileKey{
+			Kid:         key.Kid,
+			Active:      key.Active,
+			ActivatedAt: key.ActivatedAt,
+			ActivateAt:  key.ActivateAt,
+			ExpiresAt:   key.ExpiresAt,
+		}
+
+		if key.PrivateKey != nil {
+			der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+			if err != nil {
+				return fmt.Errorf("key %q: x509.MarshalPKCS8PrivateKey: %w", key.Kid, err)
+			}
+
+			item.PrivatePem = key.Kid + ".pem"
+
+			err = writeFileAtomic(filepath.Join(dir, item.PrivatePem), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+		} else {
+			der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+			if err != nil {
+				return fmt.Errorf("key %q: x509.MarshalPKIXPublicKey: %w", key.Kid, err)
+			}
+
+			item.PublicPem = key.Kid + ".pub.pem"
+
+			err = writeFileAtomic(filepath.Join(dir, item.PublicPem), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+		}
+
+		kf.Keys = append(kf.Keys, item)
+	}
+
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	return writeFileAtomic(path, data, 0o600)
+}
+
+func keyringPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+// KeyLoader loads keys from the configured pem files and keyring file
+type KeyLoader struct {
+	kid            string
+	privatePemPath string
+	publicPemPath  string
+	keyringPath    string
+}
+
+func NewKeyLoader(kid, privatePemPath, publicPemPath, keyringPath string) *KeyLoader {
+	return &KeyLoader{
+		kid:            kid,
+		privatePemPath: privatePemPath,
+		publicPemPath:  publicPemPath,
+		keyringPath:    keyringPath,
+	}
+}
+
+func (l *KeyLoader) IsEmpty() bool {
+	return l.privatePemPath == "" && l.publicPemPath == "" && l.keyringPath == ""
+}
+
+// Load reads and parses all key files
+func (l *KeyLoader) Load() ([]*model.Key, error) {
+	var err error
+
+	keys := make([]*model.Key, 0)
+
+	if l.keyringPath != "" {
+		keys, err = LoadKeyring(l.keyringPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if l.privatePemPath != "" || l.publicPemPath != "" {
+		var privatePem []byte
+		var publicPem []byte
+
+		if l.privatePemPath != "" {
+			privatePem, err = os.ReadFile(l.privatePemPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if l.publicPemPath != "" {
+			publicPem, err = os.ReadFile(l.publicPemPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		key, err := ParseKey(l.kid, privatePem, publicPem)
+		if err != nil {
+			return nil, err
+		}
+
+		// active, unless the keyring defines its own active key
+		key.Active = key.PrivateKey != nil && !slices.ContainsFunc(keys, func(k *model.Key) bool { return k.Active })
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Fingerprint returns hash of all key files content
+func (l *KeyLoader) Fingerprint() (string, error) {
+	h := sha256.New()
+
+	for _, path := range []string{l.privatePemPath, l.publicPemPath} {
+		if err := hashFile(h, path); err != nil {
+			return "", err
+		}
+	}
+
+	if l.keyringPath != "" {
+		data, err := os.ReadFile(l.keyringPath)
+		if err != nil {
+			return "", err
+		}
+
+		h.Write(data)
+
+		var kf keyringFile
+		if err = json.Unmarshal(data, &kf); err != nil {
+			return "", fmt.Errorf("fail to parse keyring file: %w", err)
+		}
+
+		dir := filepath.Dir(l.keyringPath)
+
+		for _, item := range kf.Keys {
+			for _, path := range []string{item.PrivatePem, item.PublicPem} {
+				if path != "" {
+					path = keyringPath(dir, path)
+				}
+
+				if err = hashFile(h, path); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h hash.Hash, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	h.Write([]byte(path))
+	h.Write(data)
+
+	return nil
+}
+
+// KeyReloader polls key files and swaps the keyring when they change.
+// Malformed files are rejected and the current keys are kept
+type KeyReloader struct {
+	jwtsService *Service
+	loader      *KeyLoader
+	interval    time.Duration
+	onChange    func() error
+
+	fingerprint string
+}
+
+func NewKeyReloader(jwtsService *Service, loader *KeyLoader, interval time.Duration, onChange func() error) *KeyReloader {
+	r := &KeyReloader{
+		jwtsService: jwtsService,
+		loader:      loader,
+		interval:    interval,
+		onChange:    onChange,
+	}
+
+	r.fingerprint, _ = loader.Fingerprint()
+
+	return r
+}
+
+func (r *KeyReloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+func (r *KeyReloader) check() {
+	fingerprint, err := r.loader.Fingerprint()
+	if err != nil {
+		// files can be in the middle of an update
+		slog.Warn("fail to read key files", "error", err)
+		return
+	}
+
+	if fingerprint == r.fingerprint {
+		return
+	}
+
+	// do not retry the same content
+	r.fingerprint = fingerprint
+
+	if err = r.reload(); err != nil {
+		slog.Error("key files changed, keeping current keys", "error", err)
+		return
+	}
+
+	slog.Info("keys reloaded")
+
+	if r.onChange != nil {
+		if err = r.onChange(); err != nil {
+			slog.Error("key reload onChange error", "error", err)
+		}
+	}
+}
+
+func (r *KeyReloader) reload() error {
+	keys, err := r.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	return r.jwtsService.SetStaticKeys(keys)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyReloader(t *testing.T) {
+	dir := t.TempDir()
+	privatePemPath := filepath.Join(dir, "private.pem")
+
+	writePem := func(alg string) {
+		key, err := GenerateKey("", alg)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(privatePemPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	}
+
+	writePem("ES256")
+
+	loader := NewKeyLoader("k1", privatePemPath, "", "")
+
+	keys, err := loader.Load()
+	require.NoError(t, err)
+
+	jwtsService := New()
+	require.NoError(t, jwtsService.SetKeys(keys))
+	require.Equal(t, "ES256", jwtsService.GetActiveKey().Alg)
+
+	changes := 0
+	r := NewKeyReloader(jwtsService, loader, 0, func() error {
+		changes++
+		return nil
+	})
+
+	// unchanged
+	r.check()
+	require.Equal(t, 0, changes)
+
+	// replaced
+	writePem("EdDSA")
+	r.check()
+	require.Equal(t, 1, changes)
+	require.Equal(t, "EdDSA", jwtsService.GetActiveKey().Alg)
+
+	// malformed replacement is rejected
+	require.NoError(t, os.WriteFile(privatePemPath, []byte("garbage"), 0o600))
+	r.check()
+	require.Equal(t, 1, changes)
+	require.Equal(t, "EdDSA", jwtsService.GetActiveKey().Alg)
+}
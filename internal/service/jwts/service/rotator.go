@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+const rotatorCheckInterval = 10 * time.Second
+
+// Rotator generates new signing keys on interval.
+// New key is published (verification only) for prePublish duration before it becomes active,
+// previous active key is kept for verification for retireAfter duration.
+// Rotation is for a single instance: replicas would generate their own keys, and the state file has no locking
+type Rotator struct {
+	jwtsService *Service
+	alg         string
+	interval    time.Duration
+	prePublish  time.Duration
+	retireAfter time.Duration
+	stateFile   string // optional, to keep generated keys across restarts
+	onChange    func() error
+}
+
+func NewRotator(
+	jwtsService *Service,
+	alg string,
+	interval time.Duration,
+	prePublish time.Duration,
+	retireAfter time.Duration,
+	dir string,
+	onChange func() error,
+) (*Rotator, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("rotation interval must be positive")
+	}
+
+	if prePublish < 0 || prePublish >= interval {
+		return nil, fmt.Errorf("pre-publication delay must be less than rotation interval")
+	}
+
+	if retireAfter <= 0 {
+		return nil, fmt.Errorf("retire delay must be positive")
+	}
+
+	switch alg {
+	case "RS256", "ES256", "ES384", "ES512", "EdDSA":
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	r := &Rotator{
+		jwtsService: jwtsService,
+		alg:         alg,
+		interval:    interval,
+		prePublish:  prePublish,
+		retireAfter: retireAfter,
+		onChange:    onChange,
+	}
+
+	if dir != "" {
+		r.stateFile = filepath.Join(dir, "keyring.json")
+	}
+
+	return r, nil
+}
+
+// CacheMaxAge returns max cache time of the published key set,
+// so consumers see a pre-published key before it becomes active
+func (r *Rotator) CacheMaxAge() time.Duration {
+	return r.prePublish / 2
+}
+
+// Load adds keys generated before restart into the keyring
+func (r *Rotator) Load() error {
+	if r.stateFile == "" {
+		return nil
+	}
+
+	keys, err := LoadKeyring(r.stateFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+
+	var activeKey *model.Key
+
+	for _, key := range keys {
+		if key.IsExpired(now) {
+			continue
+		}
+
+		key.Generated = true
+
+		if key.Active {
+			activeKey = key
+			key.Active = false
+		}
+
+		if err = r.jwtsService.AddKey(key); err != nil {
+			return err
+		}
+	}
+
+	if activeKey != nil {
+		err = r.jwtsService.ActivateKey(activeKey.Kid, activeKey.ActivatedAt, now.Add(r.retireAfter))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Rotator) Run(ctx context.Context) {
+	r.check()
+
+	ticker := time.NewTicker(rotatorCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+func (r *Rotator) check() {
+	changed, err := r.rotate(time.Now())
+	if err != nil {
+		slog.Error("key rotation error", "error", err)
+	}
+
+	if !changed {
+		return
+	}
+
+	if r.stateFile != "" {
+		if err = r.save(); err != nil {
+			slog.Error("fail to save rotated keys", "error", err)
+		}
+	}
+
+	if r.onChange != nil {
+		if err = r.onChange(); err != nil {
+			slog.Error("key rotation onChange error", "error", err)
+		}
+	}
+}
+
+func (r *Rotator) rotate(now time.Time) (bool, error) {
+	changed := r.jwtsService.RemoveExpiredKeys() > 0
+
+	activeKey := r.jwtsService.GetActiveKey()
+
+	var pendingKey *model.Key
+
+	for _, key := range r.jwtsService.GetKeys() {
+		if key.Generated && !key.Active && !key.ActivateAt.IsZero() {
+			pendingKey = key
+		}
+	}
+
+	// pre-publish next key
+	if pendingKey == nil && (activeKey == nil || !now.Before(activeKey.ActivatedAt.Add(r.interval-r.prePublish))) {
+		kid, err := newKid(now)
+		if err != nil {
+			return changed, err
+		}
+
+		pendingKey, err = GenerateKey(kid, r.alg)
+		if err != nil {
+			return changed, err
+		}
+
+		pendingKey.Generated = true
+		pendingKey.ActivateAt = now.Add(r.prePublish)
+
+		// nobody can have tokens to verify, so no need to wait
+		if activeKey == nil {
+			pendingKey.ActivateAt = now
+		}
+
+		if err = r.jwtsService.AddKey(pendingKey); err != nil {
+			return changed, err
+		}
+
+		slog.Info("key pre-published", "kid", pendingKey.Kid, "activate_at", pendingKey.ActivateAt)
+
+		changed = true
+	}
+
+	// activate pre-published key
+	if pendingKey != nil && !now.Before(pendingKey.ActivateAt) {
+		err := r.jwtsService.ActivateKey(pendingKey.Kid, now, now.Add(r.retireAfter))
+		if err != nil {
+			return changed, err
+		}
+
+		slog.Info("key activated", "kid", pendingKey.Kid)
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+func (r *Rotator) save() error {
+	keys := make([]*model.Key, 0)
+
+	for _, key := range r.jwtsService.GetKeys() {
+		if key.Generated {
+			keys = append(keys, key)
+		}
+	}
+
+	return SaveKeyring(r.stateFile, keys)
+}
+
+func newKid(now time.Time) (string, error) {
+	b := make([]byte, 4)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return now.UTC().Format("20060102150405") + "-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rendau/jwts/internal/service/jwts/model"
+)
+
+func TestRotator(t *testing.T) {
+	dir := t.TempDir()
+
+	jwtsService := New()
+
+	r, err := NewRotator(jwtsService, "ES256", 24*time.Hour, time.Hour, 48*time.Hour, dir, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	// empty keyring - key is activated at once
+	changed, err := r.rotate(now)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	firstKey := jwtsService.GetActiveKey()
+	require.NotNil(t, firstKey)
+	require.Len(t, jwtsService.GetKeys(), 1)
+
+	// nothing to do
+	changed, err = r.rotate(now.Add(time.Hour))
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	// next key is pre-published
+	now = now.Add(23 * time.Hour)
+	changed, err = r.rotate(now)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Len(t, jwtsService.GetKeys(), 2)
+	require.Equal(t, firstKey.Kid, jwtsService.GetActiveKey().Kid)
+
+	require.NoError(t, r.save())
+
+	// next key is activated, first one is retired
+	now = now.Add(time.Hour)
+	changed, err = r.rotate(now)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	secondKey := jwtsService.GetActiveKey()
+	require.NotEqual(t, firstKey.Kid, secondKey.Kid)
+
+	retiredKey := jwtsService.GetKey(firstKey.Kid)
+	require.NotNil(t, retiredKey)
+	require.False(t, retiredKey.Active)
+	require.Equal(t, now.Add(48*time.Hour), retiredKey.ExpiresAt)
+
+	// restore keys saved before the activation
+	restored := New()
+
+	r, err = NewRotator(restored, "ES256", 24*time.Hour, time.Hour, 48*time.Hour, dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Load())
+	require.Len(t, restored.GetKeys(), 2)
+	require.Equal(t, firstKey.Kid, restored.GetActiveKey().Kid)
+	require.NotNil(t, restored.GetKey(secondKey.Kid))
+}
+
+func TestRotatorRetiresStaticKey(t *testing.T) {
+	staticKey, err := GenerateKey("static", "ES256")
+	require.NoError(t, err)
+	staticKey.Active = true
+
+	jwtsService := New()
+	require.NoError(t, jwtsService.SetStaticKeys([]*model.Key{staticKey}))
+
+	r, err := NewRotator(jwtsService, "ES256", 24*time.Hour, time.Hour, 48*time.Hour, "", nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	// next key is pre-published and activated
+	_, err = r.rotate(now)
+	require.NoError(t, err)
+
+	_, err = r.rotate(now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotEqual(t, "static", jwtsService.GetActiveKey().Kid)
+
+	retireAt := now.Add(49 * time.Hour)
+	require.Equal(t, retireAt, jwtsService.GetKey("static").ExpiresAt)
+
+	// reload of the key files keeps the static key retired
+	reloadedKey := *staticKey
+	require.NoError(t, jwtsService.SetStaticKeys([]*model.Key{&reloadedKey}))
+
+	require.NotEqual(t, "static", jwtsService.GetActiveKey().Kid)
+	require.False(t, jwtsService.GetKey("static").Active)
+	require.Equal(t, retireAt, jwtsService.GetKey("static").ExpiresAt)
+}
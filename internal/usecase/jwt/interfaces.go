@@ -4,5 +4,11 @@ import "github.com/rendau/jwts/internal/service/jwt/model"
 
 type JwtServiceI interface {
 	Create(obj *model.JwtCreateReq) (model.JwtCreateRep, error)
+	Refresh(obj *model.JwtRefreshReq) (model.JwtCreateRep, error)
+	Exchange(obj *model.JwtExchangeReq) (model.JwtExchangeRep, error)
 	Validate(obj *model.JwtValidateReq) (*model.JwtValidateRep, error)
+	Revoke(obj *model.JwtRevokeReq) error
+	RevokeSubject(obj *model.SubjectCutoff) error
+	ListSubjectCutoffs() ([]*model.SubjectCutoff, error)
+	ClearSubjectCutoff(sub string) error
 }
@@ -27,6 +27,24 @@ func (u *Usecase) Create(obj *model.JwtCreateReq) (model.JwtCreateRep, error) {
 	return result, err
 }
 
+func (u *Usecase) Refresh(obj *model.JwtRefreshReq) (model.JwtCreateRep, error) {
+	result, err := u.srv.Refresh(obj)
+	if err != nil {
+		err = fmt.Errorf("srv.Refresh: %w", err)
+	}
+
+	return result, err
+}
+
+func (u *Usecase) Exchange(obj *model.JwtExchangeReq) (model.JwtExchangeRep, error) {
+	result, err := u.srv.Exchange(obj)
+	if err != nil {
+		err = fmt.Errorf("srv.Exchange: %w", err)
+	}
+
+	return result, err
+}
+
 func (u *Usecase) Validate(obj *model.JwtValidateReq) (*model.JwtValidateRep, error) {
 	result, err := u.srv.Validate(obj)
 	if err != nil {
@@ -35,3 +53,39 @@ func (u *Usecase) Validate(obj *model.JwtValidateReq) (*model.JwtValidateRep, er
 
 	return result, err
 }
+
+func (u *Usecase) Revoke(obj *model.JwtRevokeReq) error {
+	err := u.srv.Revoke(obj)
+	if err != nil {
+		err = fmt.Errorf("srv.Revoke: %w", err)
+	}
+
+	return err
+}
+
+func (u *Usecase) RevokeSubject(obj *model.SubjectCutoff) error {
+	err := u.srv.RevokeSubject(obj)
+	if err != nil {
+		err = fmt.Errorf("srv.RevokeSubject: %w", err)
+	}
+
+	return err
+}
+
+func (u *Usecase) ListSubjectCutoffs() ([]*model.SubjectCutoff, error) {
+	result, err := u.srv.ListSubjectCutoffs()
+	if err != nil {
+		err = fmt.Errorf("srv.ListSubjectCutoffs: %w", err)
+	}
+
+	return result, err
+}
+
+func (u *Usecase) ClearSubjectCutoff(sub string) error {
+	err := u.srv.ClearSubjectCutoff(sub)
+	if err != nil {
+		err = fmt.Errorf("srv.ClearSubjectCutoff: %w", err)
+	}
+
+	return err
+}
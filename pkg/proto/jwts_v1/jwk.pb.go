@@ -74,6 +74,9 @@ type JwkMain struct {
 	Alg           string                 `protobuf:"bytes,4,opt,name=alg,proto3" json:"alg,omitempty"`
 	N             string                 `protobuf:"bytes,5,opt,name=n,proto3" json:"n,omitempty"`
 	Use           string                 `protobuf:"bytes,6,opt,name=use,proto3" json:"use,omitempty"`
+	Crv           string                 `protobuf:"bytes,7,opt,name=crv,proto3" json:"crv,omitempty"`
+	X             string                 `protobuf:"bytes,8,opt,name=x,proto3" json:"x,omitempty"`
+	Y             string                 `protobuf:"bytes,9,opt,name=y,proto3" json:"y,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -150,20 +153,44 @@ func (x *JwkMain) GetUse() string {
 	return ""
 }
 
+func (x *JwkMain) GetCrv() string {
+	if x != nil {
+		return x.Crv
+	}
+	return ""
+}
+
+func (x *JwkMain) GetX() string {
+	if x != nil {
+		return x.X
+	}
+	return ""
+}
+
+func (x *JwkMain) GetY() string {
+	if x != nil {
+		return x.Y
+	}
+	return ""
+}
+
 var File_jwts_v1_jwk_proto protoreflect.FileDescriptor
 
 const file_jwts_v1_jwk_proto_rawDesc = "" +
 	"\n" +
 	"\x11jwts_v1/jwk.proto\x12\ajwts_v1\x1a\x1bgoogle/protobuf/empty.proto\".\n" +
 	"\x06JwkSet\x12$\n" +
-	"\x04keys\x18\x01 \x03(\v2\x10.jwts_v1.JwkMainR\x04keys\"m\n" +
+	"\x04keys\x18\x01 \x03(\v2\x10.jwts_v1.JwkMainR\x04keys\"\x9b\x01\n" +
 	"\aJwkMain\x12\x10\n" +
 	"\x03kty\x18\x01 \x01(\tR\x03kty\x12\f\n" +
 	"\x01e\x18\x02 \x01(\tR\x01e\x12\x10\n" +
 	"\x03kid\x18\x03 \x01(\tR\x03kid\x12\x10\n" +
 	"\x03alg\x18\x04 \x01(\tR\x03alg\x12\f\n" +
 	"\x01n\x18\x05 \x01(\tR\x01n\x12\x10\n" +
-	"\x03use\x18\x06 \x01(\tR\x03use25\n" +
+	"\x03use\x18\x06 \x01(\tR\x03use\x12\x10\n" +
+	"\x03crv\x18\a \x01(\tR\x03crv\x12\f\n" +
+	"\x01x\x18\b \x01(\tR\x01x\x12\f\n" +
+	"\x01y\x18\t \x01(\tR\x01y25\n" +
 	"\x03Jwk\x12.\n" +
 	"\x03Get\x12\x16.google.protobuf.Empty\x1a\x0f.jwts_v1.JwkSetB\n" +
 	"Z\b/jwts_v1b\x06proto3"
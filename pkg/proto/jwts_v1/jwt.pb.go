@@ -9,6 +9,7 @@ package jwts_v1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,13 +22,111 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type JwtInvalidReason int32
+
+const (
+	JwtInvalidReason_JWT_INVALID_REASON_NONE             JwtInvalidReason = 0
+	JwtInvalidReason_JWT_INVALID_REASON_MALFORMED        JwtInvalidReason = 1
+	JwtInvalidReason_JWT_INVALID_REASON_BAD_SIGNATURE    JwtInvalidReason = 2
+	JwtInvalidReason_JWT_INVALID_REASON_UNKNOWN_KID      JwtInvalidReason = 3
+	JwtInvalidReason_JWT_INVALID_REASON_ALG_MISMATCH     JwtInvalidReason = 4 // algorithm is not allowed for the key
+	JwtInvalidReason_JWT_INVALID_REASON_EXPIRED          JwtInvalidReason = 5
+	JwtInvalidReason_JWT_INVALID_REASON_NOT_YET_VALID    JwtInvalidReason = 6 // nbf or iat in the future
+	JwtInvalidReason_JWT_INVALID_REASON_INVALID_ISSUER   JwtInvalidReason = 7
+	JwtInvalidReason_JWT_INVALID_REASON_INVALID_AUDIENCE JwtInvalidReason = 8
+	JwtInvalidReason_JWT_INVALID_REASON_MISSING_CLAIM    JwtInvalidReason = 9
+	JwtInvalidReason_JWT_INVALID_REASON_TOO_OLD          JwtInvalidReason = 10 // max age exceeded
+	JwtInvalidReason_JWT_INVALID_REASON_INVALID          JwtInvalidReason = 11 // other failures
+	JwtInvalidReason_JWT_INVALID_REASON_REVOKED          JwtInvalidReason = 12
+	JwtInvalidReason_JWT_INVALID_REASON_INVALID_TYPE     JwtInvalidReason = 13 // unexpected "typ" header
+	JwtInvalidReason_JWT_INVALID_REASON_UNSUPPORTED_CRIT JwtInvalidReason = 14 // critical header extension is not supported
+)
+
+// Enum value maps for JwtInvalidReason.
+var (
+	JwtInvalidReason_name = map[int32]string{
+		0:  "JWT_INVALID_REASON_NONE",
+		1:  "JWT_INVALID_REASON_MALFORMED",
+		2:  "JWT_INVALID_REASON_BAD_SIGNATURE",
+		3:  "JWT_INVALID_REASON_UNKNOWN_KID",
+		4:  "JWT_INVALID_REASON_ALG_MISMATCH",
+		5:  "JWT_INVALID_REASON_EXPIRED",
+		6:  "JWT_INVALID_REASON_NOT_YET_VALID",
+		7:  "JWT_INVALID_REASON_INVALID_ISSUER",
+		8:  "JWT_INVALID_REASON_INVALID_AUDIENCE",
+		9:  "JWT_INVALID_REASON_MISSING_CLAIM",
+		10: "JWT_INVALID_REASON_TOO_OLD",
+		11: "JWT_INVALID_REASON_INVALID",
+		12: "JWT_INVALID_REASON_REVOKED",
+		13: "JWT_INVALID_REASON_INVALID_TYPE",
+		14: "JWT_INVALID_REASON_UNSUPPORTED_CRIT",
+	}
+	JwtInvalidReason_value = map[string]int32{
+		"JWT_INVALID_REASON_NONE":             0,
+		"JWT_INVALID_REASON_MALFORMED":        1,
+		"JWT_INVALID_REASON_BAD_SIGNATURE":    2,
+		"JWT_INVALID_REASON_UNKNOWN_KID":      3,
+		"JWT_INVALID_REASON_ALG_MISMATCH":     4,
+		"JWT_INVALID_REASON_EXPIRED":          5,
+		"JWT_INVALID_REASON_NOT_YET_VALID":    6,
+		"JWT_INVALID_REASON_INVALID_ISSUER":   7,
+		"JWT_INVALID_REASON_INVALID_AUDIENCE": 8,
+		"JWT_INVALID_REASON_MISSING_CLAIM":    9,
+		"JWT_INVALID_REASON_TOO_OLD":          10,
+		"JWT_INVALID_REASON_INVALID":          11,
+		"JWT_INVALID_REASON_REVOKED":          12,
+		"JWT_INVALID_REASON_INVALID_TYPE":     13,
+		"JWT_INVALID_REASON_UNSUPPORTED_CRIT": 14,
+	}
+)
+
+func (x JwtInvalidReason) Enum() *JwtInvalidReason {
+	p := new(JwtInvalidReason)
+	*p = x
+	return p
+}
+
+func (x JwtInvalidReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JwtInvalidReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_jwts_v1_jwt_proto_enumTypes[0].Descriptor()
+}
+
+func (JwtInvalidReason) Type() protoreflect.EnumType {
+	return &file_jwts_v1_jwt_proto_enumTypes[0]
+}
+
+func (x JwtInvalidReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JwtInvalidReason.Descriptor instead.
+func (JwtInvalidReason) EnumDescriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{0}
+}
+
 type JwtCreateReq struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sub           string                 `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
-	ExpSeconds    int64                  `protobuf:"varint,2,opt,name=exp_seconds,json=expSeconds,proto3" json:"exp_seconds,omitempty"`
-	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"` // json encoded payload
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Sub               string                 `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	ExpSeconds        int64                  `protobuf:"varint,2,opt,name=exp_seconds,json=expSeconds,proto3" json:"exp_seconds,omitempty"`                        // lifetime, exclusive with exp
+	Payload           []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`                                                 // json encoded custom claims, registered claims only if allowed by the mint policy
+	WithRefresh       bool                   `protobuf:"varint,4,opt,name=with_refresh,json=withRefresh,proto3" json:"with_refresh,omitempty"`                     // issue a refresh token too
+	RefreshExpSeconds int64                  `protobuf:"varint,5,opt,name=refresh_exp_seconds,json=refreshExpSeconds,proto3" json:"refresh_exp_seconds,omitempty"` // refresh token lifetime, default REFRESH_TOKEN_TTL
+	Iss               string                 `protobuf:"bytes,6,opt,name=iss,proto3" json:"iss,omitempty"`                                                         // one of ISSUERS, default the first one
+	Aud               []string               `protobuf:"bytes,7,rep,name=aud,proto3" json:"aud,omitempty"`
+	Nbf               int64                  `protobuf:"varint,8,opt,name=nbf,proto3" json:"nbf,omitempty"`                                  // unix time, exclusive with nbf_seconds
+	Iat               int64                  `protobuf:"varint,9,opt,name=iat,proto3" json:"iat,omitempty"`                                  // unix time, default now
+	Jti               string                 `protobuf:"bytes,10,opt,name=jti,proto3" json:"jti,omitempty"`                                  // default random, set one is prefixed with "<caller method>:<caller id>:"
+	Exp               int64                  `protobuf:"varint,11,opt,name=exp,proto3" json:"exp,omitempty"`                                 // unix time, exclusive with exp_seconds
+	NbfSeconds        int64                  `protobuf:"varint,12,opt,name=nbf_seconds,json=nbfSeconds,proto3" json:"nbf_seconds,omitempty"` // offset from now, exclusive with nbf
+	Typ               string                 `protobuf:"bytes,13,opt,name=typ,proto3" json:"typ,omitempty"`                                  // "typ" header, e.g. "at+jwt"
+	Cty               string                 `protobuf:"bytes,14,opt,name=cty,proto3" json:"cty,omitempty"`                                  // "cty" header
+	Crit              []string               `protobuf:"bytes,15,rep,name=crit,proto3" json:"crit,omitempty"`                                // critical extensions, names from CRIT_HEADERS set in header
+	Header            []byte                 `protobuf:"bytes,16,opt,name=header,proto3" json:"header,omitempty"`                            // json encoded extra header parameters, names from CREATE_HEADERS
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *JwtCreateReq) Reset() {
@@ -81,9 +180,101 @@ func (x *JwtCreateReq) GetPayload() []byte {
 	return nil
 }
 
+func (x *JwtCreateReq) GetWithRefresh() bool {
+	if x != nil {
+		return x.WithRefresh
+	}
+	return false
+}
+
+func (x *JwtCreateReq) GetRefreshExpSeconds() int64 {
+	if x != nil {
+		return x.RefreshExpSeconds
+	}
+	return 0
+}
+
+func (x *JwtCreateReq) GetIss() string {
+	if x != nil {
+		return x.Iss
+	}
+	return ""
+}
+
+func (x *JwtCreateReq) GetAud() []string {
+	if x != nil {
+		return x.Aud
+	}
+	return nil
+}
+
+func (x *JwtCreateReq) GetNbf() int64 {
+	if x != nil {
+		return x.Nbf
+	}
+	return 0
+}
+
+func (x *JwtCreateReq) GetIat() int64 {
+	if x != nil {
+		return x.Iat
+	}
+	return 0
+}
+
+func (x *JwtCreateReq) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *JwtCreateReq) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *JwtCreateReq) GetNbfSeconds() int64 {
+	if x != nil {
+		return x.NbfSeconds
+	}
+	return 0
+}
+
+func (x *JwtCreateReq) GetTyp() string {
+	if x != nil {
+		return x.Typ
+	}
+	return ""
+}
+
+func (x *JwtCreateReq) GetCty() string {
+	if x != nil {
+		return x.Cty
+	}
+	return ""
+}
+
+func (x *JwtCreateReq) GetCrit() []string {
+	if x != nil {
+		return x.Crit
+	}
+	return nil
+}
+
+func (x *JwtCreateReq) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
 type JwtCreateRep struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken  string                 `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"` // only with with_refresh, or from Refresh
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,16 +316,205 @@ func (x *JwtCreateRep) GetToken() string {
 	return ""
 }
 
-type JwtValidateReq struct {
+func (x *JwtCreateRep) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type JwtRefreshReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtRefreshReq) Reset() {
+	*x = JwtRefreshReq{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtRefreshReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtRefreshReq) ProtoMessage() {}
+
+func (x *JwtRefreshReq) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtRefreshReq.ProtoReflect.Descriptor instead.
+func (*JwtRefreshReq) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *JwtRefreshReq) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+// token exchange (RFC 8693), the caller must be a client with the token exchange grant,
+// it is the acting party without actor_token
+type JwtExchangeReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubjectToken  string                 `protobuf:"bytes,1,opt,name=subject_token,json=subjectToken,proto3" json:"subject_token,omitempty"`
+	ActorToken    string                 `protobuf:"bytes,2,opt,name=actor_token,json=actorToken,proto3" json:"actor_token,omitempty"` // optional, acting party of the delegation
+	Audiences     []string               `protobuf:"bytes,3,rep,name=audiences,proto3" json:"audiences,omitempty"`                     // requested, all granted ones if empty
+	Scopes        []string               `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`                           // requested, all granted ones if empty; narrowed to the scopes of subject_token
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtExchangeReq) Reset() {
+	*x = JwtExchangeReq{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtExchangeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtExchangeReq) ProtoMessage() {}
+
+func (x *JwtExchangeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtExchangeReq.ProtoReflect.Descriptor instead.
+func (*JwtExchangeReq) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *JwtExchangeReq) GetSubjectToken() string {
+	if x != nil {
+		return x.SubjectToken
+	}
+	return ""
+}
+
+func (x *JwtExchangeReq) GetActorToken() string {
+	if x != nil {
+		return x.ActorToken
+	}
+	return ""
+}
+
+func (x *JwtExchangeReq) GetAudiences() []string {
+	if x != nil {
+		return x.Audiences
+	}
+	return nil
+}
+
+func (x *JwtExchangeReq) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type JwtExchangeRep struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ExpSeconds    int64                  `protobuf:"varint,2,opt,name=exp_seconds,json=expSeconds,proto3" json:"exp_seconds,omitempty"`
+	Scopes        []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *JwtExchangeRep) Reset() {
+	*x = JwtExchangeRep{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtExchangeRep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtExchangeRep) ProtoMessage() {}
+
+func (x *JwtExchangeRep) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtExchangeRep.ProtoReflect.Descriptor instead.
+func (*JwtExchangeRep) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *JwtExchangeRep) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *JwtExchangeRep) GetExpSeconds() int64 {
+	if x != nil {
+		return x.ExpSeconds
+	}
+	return 0
+}
+
+func (x *JwtExchangeRep) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type JwtValidateReq struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Token                   string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Issuers                 []string               `protobuf:"bytes,2,rep,name=issuers,proto3" json:"issuers,omitempty"`                                                                   // "iss" must be one of, default VALIDATE_ISSUERS
+	Audiences               []string               `protobuf:"bytes,3,rep,name=audiences,proto3" json:"audiences,omitempty"`                                                               // "aud" must contain one of, default VALIDATE_AUDIENCES
+	RequiredClaims          []string               `protobuf:"bytes,4,rep,name=required_claims,json=requiredClaims,proto3" json:"required_claims,omitempty"`                               // default VALIDATE_REQUIRED_CLAIMS
+	LeewaySeconds           int64                  `protobuf:"varint,5,opt,name=leeway_seconds,json=leewaySeconds,proto3" json:"leeway_seconds,omitempty"`                                 // clock skew for exp, nbf, iat, default VALIDATE_LEEWAY
+	MaxAgeSeconds           int64                  `protobuf:"varint,6,opt,name=max_age_seconds,json=maxAgeSeconds,proto3" json:"max_age_seconds,omitempty"`                               // max time since "iat", default VALIDATE_MAX_AGE
+	Strict                  *bool                  `protobuf:"varint,7,opt,name=strict,proto3,oneof" json:"strict,omitempty"`                                                              // no claims for invalid tokens, default VALIDATE_STRICT
+	IncludeUnverifiedClaims bool                   `protobuf:"varint,8,opt,name=include_unverified_claims,json=includeUnverifiedClaims,proto3" json:"include_unverified_claims,omitempty"` // debug, return claims of invalid tokens in unverified_claims
+	Typ                     string                 `protobuf:"bytes,9,opt,name=typ,proto3" json:"typ,omitempty"`                                                                           // expected "typ" header, default VALIDATE_TYP
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
 func (x *JwtValidateReq) Reset() {
 	*x = JwtValidateReq{}
-	mi := &file_jwts_v1_jwt_proto_msgTypes[2]
+	mi := &file_jwts_v1_jwt_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -146,7 +526,7 @@ func (x *JwtValidateReq) String() string {
 func (*JwtValidateReq) ProtoMessage() {}
 
 func (x *JwtValidateReq) ProtoReflect() protoreflect.Message {
-	mi := &file_jwts_v1_jwt_proto_msgTypes[2]
+	mi := &file_jwts_v1_jwt_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -159,7 +539,7 @@ func (x *JwtValidateReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JwtValidateReq.ProtoReflect.Descriptor instead.
 func (*JwtValidateReq) Descriptor() ([]byte, []int) {
-	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{2}
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *JwtValidateReq) GetToken() string {
@@ -169,17 +549,76 @@ func (x *JwtValidateReq) GetToken() string {
 	return ""
 }
 
+func (x *JwtValidateReq) GetIssuers() []string {
+	if x != nil {
+		return x.Issuers
+	}
+	return nil
+}
+
+func (x *JwtValidateReq) GetAudiences() []string {
+	if x != nil {
+		return x.Audiences
+	}
+	return nil
+}
+
+func (x *JwtValidateReq) GetRequiredClaims() []string {
+	if x != nil {
+		return x.RequiredClaims
+	}
+	return nil
+}
+
+func (x *JwtValidateReq) GetLeewaySeconds() int64 {
+	if x != nil {
+		return x.LeewaySeconds
+	}
+	return 0
+}
+
+func (x *JwtValidateReq) GetMaxAgeSeconds() int64 {
+	if x != nil {
+		return x.MaxAgeSeconds
+	}
+	return 0
+}
+
+func (x *JwtValidateReq) GetStrict() bool {
+	if x != nil && x.Strict != nil {
+		return *x.Strict
+	}
+	return false
+}
+
+func (x *JwtValidateReq) GetIncludeUnverifiedClaims() bool {
+	if x != nil {
+		return x.IncludeUnverifiedClaims
+	}
+	return false
+}
+
+func (x *JwtValidateReq) GetTyp() string {
+	if x != nil {
+		return x.Typ
+	}
+	return ""
+}
+
 type JwtValidateRep struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
-	Claims        []byte                 `protobuf:"bytes,2,opt,name=claims,proto3" json:"claims,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Valid            bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Claims           []byte                 `protobuf:"bytes,2,opt,name=claims,proto3" json:"claims,omitempty"`
+	Reason           JwtInvalidReason       `protobuf:"varint,3,opt,name=reason,proto3,enum=jwts_v1.JwtInvalidReason" json:"reason,omitempty"`              // set if not valid
+	Detail           string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`                                             // human-readable reason
+	UnverifiedClaims []byte                 `protobuf:"bytes,5,opt,name=unverified_claims,json=unverifiedClaims,proto3" json:"unverified_claims,omitempty"` // claims of invalid token, only with include_unverified_claims
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *JwtValidateRep) Reset() {
 	*x = JwtValidateRep{}
-	mi := &file_jwts_v1_jwt_proto_msgTypes[3]
+	mi := &file_jwts_v1_jwt_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -191,7 +630,7 @@ func (x *JwtValidateRep) String() string {
 func (*JwtValidateRep) ProtoMessage() {}
 
 func (x *JwtValidateRep) ProtoReflect() protoreflect.Message {
-	mi := &file_jwts_v1_jwt_proto_msgTypes[3]
+	mi := &file_jwts_v1_jwt_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,7 +643,7 @@ func (x *JwtValidateRep) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JwtValidateRep.ProtoReflect.Descriptor instead.
 func (*JwtValidateRep) Descriptor() ([]byte, []int) {
-	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{3}
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *JwtValidateRep) GetValid() bool {
@@ -221,26 +660,377 @@ func (x *JwtValidateRep) GetClaims() []byte {
 	return nil
 }
 
+func (x *JwtValidateRep) GetReason() JwtInvalidReason {
+	if x != nil {
+		return x.Reason
+	}
+	return JwtInvalidReason_JWT_INVALID_REASON_NONE
+}
+
+func (x *JwtValidateRep) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *JwtValidateRep) GetUnverifiedClaims() []byte {
+	if x != nil {
+		return x.UnverifiedClaims
+	}
+	return nil
+}
+
+type JwtRevokeReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jti           string                 `protobuf:"bytes,1,opt,name=jti,proto3" json:"jti,omitempty"`
+	Exp           int64                  `protobuf:"varint,2,opt,name=exp,proto3" json:"exp,omitempty"`    // unix time, expiration of the token, the revocation is forgotten after it; required with jti
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"` // alternative to jti and exp, taken from the token
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtRevokeReq) Reset() {
+	*x = JwtRevokeReq{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtRevokeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtRevokeReq) ProtoMessage() {}
+
+func (x *JwtRevokeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtRevokeReq.ProtoReflect.Descriptor instead.
+func (*JwtRevokeReq) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *JwtRevokeReq) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *JwtRevokeReq) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *JwtRevokeReq) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type JwtRevokeSubjectReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sub           string                 `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	Before        int64                  `protobuf:"varint,2,opt,name=before,proto3" json:"before,omitempty"` // unix time, not in the future, tokens issued before are revoked, 0 - now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtRevokeSubjectReq) Reset() {
+	*x = JwtRevokeSubjectReq{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtRevokeSubjectReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtRevokeSubjectReq) ProtoMessage() {}
+
+func (x *JwtRevokeSubjectReq) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtRevokeSubjectReq.ProtoReflect.Descriptor instead.
+func (*JwtRevokeSubjectReq) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *JwtRevokeSubjectReq) GetSub() string {
+	if x != nil {
+		return x.Sub
+	}
+	return ""
+}
+
+func (x *JwtRevokeSubjectReq) GetBefore() int64 {
+	if x != nil {
+		return x.Before
+	}
+	return 0
+}
+
+type JwtSubjectCutoff struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sub           string                 `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	Before        int64                  `protobuf:"varint,2,opt,name=before,proto3" json:"before,omitempty"` // unix time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtSubjectCutoff) Reset() {
+	*x = JwtSubjectCutoff{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtSubjectCutoff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtSubjectCutoff) ProtoMessage() {}
+
+func (x *JwtSubjectCutoff) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtSubjectCutoff.ProtoReflect.Descriptor instead.
+func (*JwtSubjectCutoff) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *JwtSubjectCutoff) GetSub() string {
+	if x != nil {
+		return x.Sub
+	}
+	return ""
+}
+
+func (x *JwtSubjectCutoff) GetBefore() int64 {
+	if x != nil {
+		return x.Before
+	}
+	return 0
+}
+
+type JwtSubjectCutoffList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*JwtSubjectCutoff    `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtSubjectCutoffList) Reset() {
+	*x = JwtSubjectCutoffList{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtSubjectCutoffList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtSubjectCutoffList) ProtoMessage() {}
+
+func (x *JwtSubjectCutoffList) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtSubjectCutoffList.ProtoReflect.Descriptor instead.
+func (*JwtSubjectCutoffList) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JwtSubjectCutoffList) GetItems() []*JwtSubjectCutoff {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type JwtClearSubjectCutoffReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sub           string                 `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JwtClearSubjectCutoffReq) Reset() {
+	*x = JwtClearSubjectCutoffReq{}
+	mi := &file_jwts_v1_jwt_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JwtClearSubjectCutoffReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JwtClearSubjectCutoffReq) ProtoMessage() {}
+
+func (x *JwtClearSubjectCutoffReq) ProtoReflect() protoreflect.Message {
+	mi := &file_jwts_v1_jwt_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JwtClearSubjectCutoffReq.ProtoReflect.Descriptor instead.
+func (*JwtClearSubjectCutoffReq) Descriptor() ([]byte, []int) {
+	return file_jwts_v1_jwt_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JwtClearSubjectCutoffReq) GetSub() string {
+	if x != nil {
+		return x.Sub
+	}
+	return ""
+}
+
 var File_jwts_v1_jwt_proto protoreflect.FileDescriptor
 
 const file_jwts_v1_jwt_proto_rawDesc = "" +
 	"\n" +
-	"\x11jwts_v1/jwt.proto\x12\ajwts_v1\"[\n" +
+	"\x11jwts_v1/jwt.proto\x12\ajwts_v1\x1a\x1bgoogle/protobuf/empty.proto\"\x8b\x03\n" +
 	"\fJwtCreateReq\x12\x10\n" +
 	"\x03sub\x18\x01 \x01(\tR\x03sub\x12\x1f\n" +
 	"\vexp_seconds\x18\x02 \x01(\x03R\n" +
 	"expSeconds\x12\x18\n" +
-	"\apayload\x18\x03 \x01(\fR\apayload\"$\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x12!\n" +
+	"\fwith_refresh\x18\x04 \x01(\bR\vwithRefresh\x12.\n" +
+	"\x13refresh_exp_seconds\x18\x05 \x01(\x03R\x11refreshExpSeconds\x12\x10\n" +
+	"\x03iss\x18\x06 \x01(\tR\x03iss\x12\x10\n" +
+	"\x03aud\x18\a \x03(\tR\x03aud\x12\x10\n" +
+	"\x03nbf\x18\b \x01(\x03R\x03nbf\x12\x10\n" +
+	"\x03iat\x18\t \x01(\x03R\x03iat\x12\x10\n" +
+	"\x03jti\x18\n" +
+	" \x01(\tR\x03jti\x12\x10\n" +
+	"\x03exp\x18\v \x01(\x03R\x03exp\x12\x1f\n" +
+	"\vnbf_seconds\x18\f \x01(\x03R\n" +
+	"nbfSeconds\x12\x10\n" +
+	"\x03typ\x18\r \x01(\tR\x03typ\x12\x10\n" +
+	"\x03cty\x18\x0e \x01(\tR\x03cty\x12\x12\n" +
+	"\x04crit\x18\x0f \x03(\tR\x04crit\x12\x16\n" +
+	"\x06header\x18\x10 \x01(\fR\x06header\"I\n" +
 	"\fJwtCreateRep\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"&\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\"4\n" +
+	"\rJwtRefreshReq\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"\x8c\x01\n" +
+	"\x0eJwtExchangeReq\x12#\n" +
+	"\rsubject_token\x18\x01 \x01(\tR\fsubjectToken\x12\x1f\n" +
+	"\vactor_token\x18\x02 \x01(\tR\n" +
+	"actorToken\x12\x1c\n" +
+	"\taudiences\x18\x03 \x03(\tR\taudiences\x12\x16\n" +
+	"\x06scopes\x18\x04 \x03(\tR\x06scopes\"_\n" +
+	"\x0eJwtExchangeRep\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vexp_seconds\x18\x02 \x01(\x03R\n" +
+	"expSeconds\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\"\xcc\x02\n" +
 	"\x0eJwtValidateReq\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\">\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x18\n" +
+	"\aissuers\x18\x02 \x03(\tR\aissuers\x12\x1c\n" +
+	"\taudiences\x18\x03 \x03(\tR\taudiences\x12'\n" +
+	"\x0frequired_claims\x18\x04 \x03(\tR\x0erequiredClaims\x12%\n" +
+	"\x0eleeway_seconds\x18\x05 \x01(\x03R\rleewaySeconds\x12&\n" +
+	"\x0fmax_age_seconds\x18\x06 \x01(\x03R\rmaxAgeSeconds\x12\x1b\n" +
+	"\x06strict\x18\a \x01(\bH\x00R\x06strict\x88\x01\x01\x12:\n" +
+	"\x19include_unverified_claims\x18\b \x01(\bR\x17includeUnverifiedClaims\x12\x10\n" +
+	"\x03typ\x18\t \x01(\tR\x03typB\t\n" +
+	"\a_strict\"\xb6\x01\n" +
 	"\x0eJwtValidateRep\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
-	"\x06claims\x18\x02 \x01(\fR\x06claims2{\n" +
+	"\x06claims\x18\x02 \x01(\fR\x06claims\x121\n" +
+	"\x06reason\x18\x03 \x01(\x0e2\x19.jwts_v1.JwtInvalidReasonR\x06reason\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12+\n" +
+	"\x11unverified_claims\x18\x05 \x01(\fR\x10unverifiedClaims\"H\n" +
+	"\fJwtRevokeReq\x12\x10\n" +
+	"\x03jti\x18\x01 \x01(\tR\x03jti\x12\x10\n" +
+	"\x03exp\x18\x02 \x01(\x03R\x03exp\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"?\n" +
+	"\x13JwtRevokeSubjectReq\x12\x10\n" +
+	"\x03sub\x18\x01 \x01(\tR\x03sub\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\x03R\x06before\"<\n" +
+	"\x10JwtSubjectCutoff\x12\x10\n" +
+	"\x03sub\x18\x01 \x01(\tR\x03sub\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\x03R\x06before\"G\n" +
+	"\x14JwtSubjectCutoffList\x12/\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.jwts_v1.JwtSubjectCutoffR\x05items\",\n" +
+	"\x18JwtClearSubjectCutoffReq\x12\x10\n" +
+	"\x03sub\x18\x01 \x01(\tR\x03sub*\xaa\x04\n" +
+	"\x10JwtInvalidReason\x12\x1b\n" +
+	"\x17JWT_INVALID_REASON_NONE\x10\x00\x12 \n" +
+	"\x1cJWT_INVALID_REASON_MALFORMED\x10\x01\x12$\n" +
+	" JWT_INVALID_REASON_BAD_SIGNATURE\x10\x02\x12\"\n" +
+	"\x1eJWT_INVALID_REASON_UNKNOWN_KID\x10\x03\x12#\n" +
+	"\x1fJWT_INVALID_REASON_ALG_MISMATCH\x10\x04\x12\x1e\n" +
+	"\x1aJWT_INVALID_REASON_EXPIRED\x10\x05\x12$\n" +
+	" JWT_INVALID_REASON_NOT_YET_VALID\x10\x06\x12%\n" +
+	"!JWT_INVALID_REASON_INVALID_ISSUER\x10\a\x12'\n" +
+	"#JWT_INVALID_REASON_INVALID_AUDIENCE\x10\b\x12$\n" +
+	" JWT_INVALID_REASON_MISSING_CLAIM\x10\t\x12\x1e\n" +
+	"\x1aJWT_INVALID_REASON_TOO_OLD\x10\n" +
+	"\x12\x1e\n" +
+	"\x1aJWT_INVALID_REASON_INVALID\x10\v\x12\x1e\n" +
+	"\x1aJWT_INVALID_REASON_REVOKED\x10\f\x12#\n" +
+	"\x1fJWT_INVALID_REASON_INVALID_TYPE\x10\r\x12'\n" +
+	"#JWT_INVALID_REASON_UNSUPPORTED_CRIT\x10\x0e2\x91\x04\n" +
 	"\x03Jwt\x126\n" +
-	"\x06Create\x12\x15.jwts_v1.JwtCreateReq\x1a\x15.jwts_v1.JwtCreateRep\x12<\n" +
-	"\bValidate\x12\x17.jwts_v1.JwtValidateReq\x1a\x17.jwts_v1.JwtValidateRepB\n" +
+	"\x06Create\x12\x15.jwts_v1.JwtCreateReq\x1a\x15.jwts_v1.JwtCreateRep\x128\n" +
+	"\aRefresh\x12\x16.jwts_v1.JwtRefreshReq\x1a\x15.jwts_v1.JwtCreateRep\x12<\n" +
+	"\bExchange\x12\x17.jwts_v1.JwtExchangeReq\x1a\x17.jwts_v1.JwtExchangeRep\x12<\n" +
+	"\bValidate\x12\x17.jwts_v1.JwtValidateReq\x1a\x17.jwts_v1.JwtValidateRep\x127\n" +
+	"\x06Revoke\x12\x15.jwts_v1.JwtRevokeReq\x1a\x16.google.protobuf.Empty\x12E\n" +
+	"\rRevokeSubject\x12\x1c.jwts_v1.JwtRevokeSubjectReq\x1a\x16.google.protobuf.Empty\x12K\n" +
+	"\x12ListSubjectCutoffs\x12\x16.google.protobuf.Empty\x1a\x1d.jwts_v1.JwtSubjectCutoffList\x12O\n" +
+	"\x12ClearSubjectCutoff\x12!.jwts_v1.JwtClearSubjectCutoffReq\x1a\x16.google.protobuf.EmptyB\n" +
 	"Z\b/jwts_v1b\x06proto3"
 
 var (
@@ -255,23 +1045,48 @@ func file_jwts_v1_jwt_proto_rawDescGZIP() []byte {
 	return file_jwts_v1_jwt_proto_rawDescData
 }
 
-var file_jwts_v1_jwt_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_jwts_v1_jwt_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_jwts_v1_jwt_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_jwts_v1_jwt_proto_goTypes = []any{
-	(*JwtCreateReq)(nil),   // 0: jwts_v1.JwtCreateReq
-	(*JwtCreateRep)(nil),   // 1: jwts_v1.JwtCreateRep
-	(*JwtValidateReq)(nil), // 2: jwts_v1.JwtValidateReq
-	(*JwtValidateRep)(nil), // 3: jwts_v1.JwtValidateRep
+	(JwtInvalidReason)(0),            // 0: jwts_v1.JwtInvalidReason
+	(*JwtCreateReq)(nil),             // 1: jwts_v1.JwtCreateReq
+	(*JwtCreateRep)(nil),             // 2: jwts_v1.JwtCreateRep
+	(*JwtRefreshReq)(nil),            // 3: jwts_v1.JwtRefreshReq
+	(*JwtExchangeReq)(nil),           // 4: jwts_v1.JwtExchangeReq
+	(*JwtExchangeRep)(nil),           // 5: jwts_v1.JwtExchangeRep
+	(*JwtValidateReq)(nil),           // 6: jwts_v1.JwtValidateReq
+	(*JwtValidateRep)(nil),           // 7: jwts_v1.JwtValidateRep
+	(*JwtRevokeReq)(nil),             // 8: jwts_v1.JwtRevokeReq
+	(*JwtRevokeSubjectReq)(nil),      // 9: jwts_v1.JwtRevokeSubjectReq
+	(*JwtSubjectCutoff)(nil),         // 10: jwts_v1.JwtSubjectCutoff
+	(*JwtSubjectCutoffList)(nil),     // 11: jwts_v1.JwtSubjectCutoffList
+	(*JwtClearSubjectCutoffReq)(nil), // 12: jwts_v1.JwtClearSubjectCutoffReq
+	(*emptypb.Empty)(nil),            // 13: google.protobuf.Empty
 }
 var file_jwts_v1_jwt_proto_depIdxs = []int32{
-	0, // 0: jwts_v1.Jwt.Create:input_type -> jwts_v1.JwtCreateReq
-	2, // 1: jwts_v1.Jwt.Validate:input_type -> jwts_v1.JwtValidateReq
-	1, // 2: jwts_v1.Jwt.Create:output_type -> jwts_v1.JwtCreateRep
-	3, // 3: jwts_v1.Jwt.Validate:output_type -> jwts_v1.JwtValidateRep
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: jwts_v1.JwtValidateRep.reason:type_name -> jwts_v1.JwtInvalidReason
+	10, // 1: jwts_v1.JwtSubjectCutoffList.items:type_name -> jwts_v1.JwtSubjectCutoff
+	1,  // 2: jwts_v1.Jwt.Create:input_type -> jwts_v1.JwtCreateReq
+	3,  // 3: jwts_v1.Jwt.Refresh:input_type -> jwts_v1.JwtRefreshReq
+	4,  // 4: jwts_v1.Jwt.Exchange:input_type -> jwts_v1.JwtExchangeReq
+	6,  // 5: jwts_v1.Jwt.Validate:input_type -> jwts_v1.JwtValidateReq
+	8,  // 6: jwts_v1.Jwt.Revoke:input_type -> jwts_v1.JwtRevokeReq
+	9,  // 7: jwts_v1.Jwt.RevokeSubject:input_type -> jwts_v1.JwtRevokeSubjectReq
+	13, // 8: jwts_v1.Jwt.ListSubjectCutoffs:input_type -> google.protobuf.Empty
+	12, // 9: jwts_v1.Jwt.ClearSubjectCutoff:input_type -> jwts_v1.JwtClearSubjectCutoffReq
+	2,  // 10: jwts_v1.Jwt.Create:output_type -> jwts_v1.JwtCreateRep
+	2,  // 11: jwts_v1.Jwt.Refresh:output_type -> jwts_v1.JwtCreateRep
+	5,  // 12: jwts_v1.Jwt.Exchange:output_type -> jwts_v1.JwtExchangeRep
+	7,  // 13: jwts_v1.Jwt.Validate:output_type -> jwts_v1.JwtValidateRep
+	13, // 14: jwts_v1.Jwt.Revoke:output_type -> google.protobuf.Empty
+	13, // 15: jwts_v1.Jwt.RevokeSubject:output_type -> google.protobuf.Empty
+	11, // 16: jwts_v1.Jwt.ListSubjectCutoffs:output_type -> jwts_v1.JwtSubjectCutoffList
+	13, // 17: jwts_v1.Jwt.ClearSubjectCutoff:output_type -> google.protobuf.Empty
+	10, // [10:18] is the sub-list for method output_type
+	2,  // [2:10] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_jwts_v1_jwt_proto_init() }
@@ -279,18 +1094,20 @@ func file_jwts_v1_jwt_proto_init() {
 	if File_jwts_v1_jwt_proto != nil {
 		return
 	}
+	file_jwts_v1_jwt_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jwts_v1_jwt_proto_rawDesc), len(file_jwts_v1_jwt_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_jwts_v1_jwt_proto_goTypes,
 		DependencyIndexes: file_jwts_v1_jwt_proto_depIdxs,
+		EnumInfos:         file_jwts_v1_jwt_proto_enumTypes,
 		MessageInfos:      file_jwts_v1_jwt_proto_msgTypes,
 	}.Build()
 	File_jwts_v1_jwt_proto = out.File
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,8 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Jwt_Create_FullMethodName   = "/jwts_v1.Jwt/Create"
-	Jwt_Validate_FullMethodName = "/jwts_v1.Jwt/Validate"
+	Jwt_Create_FullMethodName             = "/jwts_v1.Jwt/Create"
+	Jwt_Refresh_FullMethodName            = "/jwts_v1.Jwt/Refresh"
+	Jwt_Exchange_FullMethodName           = "/jwts_v1.Jwt/Exchange"
+	Jwt_Validate_FullMethodName           = "/jwts_v1.Jwt/Validate"
+	Jwt_Revoke_FullMethodName             = "/jwts_v1.Jwt/Revoke"
+	Jwt_RevokeSubject_FullMethodName      = "/jwts_v1.Jwt/RevokeSubject"
+	Jwt_ListSubjectCutoffs_FullMethodName = "/jwts_v1.Jwt/ListSubjectCutoffs"
+	Jwt_ClearSubjectCutoff_FullMethodName = "/jwts_v1.Jwt/ClearSubjectCutoff"
 )
 
 // JwtClient is the client API for Jwt service.
@@ -28,7 +35,13 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type JwtClient interface {
 	Create(ctx context.Context, in *JwtCreateReq, opts ...grpc.CallOption) (*JwtCreateRep, error)
+	Refresh(ctx context.Context, in *JwtRefreshReq, opts ...grpc.CallOption) (*JwtCreateRep, error)
+	Exchange(ctx context.Context, in *JwtExchangeReq, opts ...grpc.CallOption) (*JwtExchangeRep, error)
 	Validate(ctx context.Context, in *JwtValidateReq, opts ...grpc.CallOption) (*JwtValidateRep, error)
+	Revoke(ctx context.Context, in *JwtRevokeReq, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RevokeSubject(ctx context.Context, in *JwtRevokeSubjectReq, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListSubjectCutoffs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JwtSubjectCutoffList, error)
+	ClearSubjectCutoff(ctx context.Context, in *JwtClearSubjectCutoffReq, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type jwtClient struct {
@@ -49,6 +62,26 @@ func (c *jwtClient) Create(ctx context.Context, in *JwtCreateReq, opts ...grpc.C
 	return out, nil
 }
 
+func (c *jwtClient) Refresh(ctx context.Context, in *JwtRefreshReq, opts ...grpc.CallOption) (*JwtCreateRep, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JwtCreateRep)
+	err := c.cc.Invoke(ctx, Jwt_Refresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jwtClient) Exchange(ctx context.Context, in *JwtExchangeReq, opts ...grpc.CallOption) (*JwtExchangeRep, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JwtExchangeRep)
+	err := c.cc.Invoke(ctx, Jwt_Exchange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jwtClient) Validate(ctx context.Context, in *JwtValidateReq, opts ...grpc.CallOption) (*JwtValidateRep, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(JwtValidateRep)
@@ -59,12 +92,58 @@ func (c *jwtClient) Validate(ctx context.Context, in *JwtValidateReq, opts ...gr
 	return out, nil
 }
 
+func (c *jwtClient) Revoke(ctx context.Context, in *JwtRevokeReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Jwt_Revoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jwtClient) RevokeSubject(ctx context.Context, in *JwtRevokeSubjectReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Jwt_RevokeSubject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jwtClient) ListSubjectCutoffs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JwtSubjectCutoffList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JwtSubjectCutoffList)
+	err := c.cc.Invoke(ctx, Jwt_ListSubjectCutoffs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jwtClient) ClearSubjectCutoff(ctx context.Context, in *JwtClearSubjectCutoffReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Jwt_ClearSubjectCutoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // JwtServer is the server API for Jwt service.
 // All implementations must embed UnimplementedJwtServer
 // for forward compatibility.
 type JwtServer interface {
 	Create(context.Context, *JwtCreateReq) (*JwtCreateRep, error)
+	Refresh(context.Context, *JwtRefreshReq) (*JwtCreateRep, error)
+	Exchange(context.Context, *JwtExchangeReq) (*JwtExchangeRep, error)
 	Validate(context.Context, *JwtValidateReq) (*JwtValidateRep, error)
+	Revoke(context.Context, *JwtRevokeReq) (*emptypb.Empty, error)
+	RevokeSubject(context.Context, *JwtRevokeSubjectReq) (*emptypb.Empty, error)
+	ListSubjectCutoffs(context.Context, *emptypb.Empty) (*JwtSubjectCutoffList, error)
+	ClearSubjectCutoff(context.Context, *JwtClearSubjectCutoffReq) (*emptypb.Empty, error)
 	mustEmbedUnimplementedJwtServer()
 }
 
@@ -78,9 +157,27 @@ type UnimplementedJwtServer struct{}
 func (UnimplementedJwtServer) Create(context.Context, *JwtCreateReq) (*JwtCreateRep, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedJwtServer) Refresh(context.Context, *JwtRefreshReq) (*JwtCreateRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
+func (UnimplementedJwtServer) Exchange(context.Context, *JwtExchangeReq) (*JwtExchangeRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exchange not implemented")
+}
 func (UnimplementedJwtServer) Validate(context.Context, *JwtValidateReq) (*JwtValidateRep, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
 }
+func (UnimplementedJwtServer) Revoke(context.Context, *JwtRevokeReq) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Revoke not implemented")
+}
+func (UnimplementedJwtServer) RevokeSubject(context.Context, *JwtRevokeSubjectReq) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSubject not implemented")
+}
+func (UnimplementedJwtServer) ListSubjectCutoffs(context.Context, *emptypb.Empty) (*JwtSubjectCutoffList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubjectCutoffs not implemented")
+}
+func (UnimplementedJwtServer) ClearSubjectCutoff(context.Context, *JwtClearSubjectCutoffReq) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearSubjectCutoff not implemented")
+}
 func (UnimplementedJwtServer) mustEmbedUnimplementedJwtServer() {}
 func (UnimplementedJwtServer) testEmbeddedByValue()             {}
 
@@ -120,6 +217,42 @@ func _Jwt_Create_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Jwt_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JwtRefreshReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_Refresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).Refresh(ctx, req.(*JwtRefreshReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jwt_Exchange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JwtExchangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).Exchange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_Exchange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).Exchange(ctx, req.(*JwtExchangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Jwt_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(JwtValidateReq)
 	if err := dec(in); err != nil {
@@ -138,6 +271,78 @@ func _Jwt_Validate_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Jwt_Revoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JwtRevokeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).Revoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_Revoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).Revoke(ctx, req.(*JwtRevokeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jwt_RevokeSubject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JwtRevokeSubjectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).RevokeSubject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_RevokeSubject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).RevokeSubject(ctx, req.(*JwtRevokeSubjectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jwt_ListSubjectCutoffs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).ListSubjectCutoffs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_ListSubjectCutoffs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).ListSubjectCutoffs(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jwt_ClearSubjectCutoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JwtClearSubjectCutoffReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JwtServer).ClearSubjectCutoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Jwt_ClearSubjectCutoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JwtServer).ClearSubjectCutoff(ctx, req.(*JwtClearSubjectCutoffReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Jwt_ServiceDesc is the grpc.ServiceDesc for Jwt service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -149,10 +354,34 @@ var Jwt_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _Jwt_Create_Handler,
 		},
+		{
+			MethodName: "Refresh",
+			Handler:    _Jwt_Refresh_Handler,
+		},
+		{
+			MethodName: "Exchange",
+			Handler:    _Jwt_Exchange_Handler,
+		},
 		{
 			MethodName: "Validate",
 			Handler:    _Jwt_Validate_Handler,
 		},
+		{
+			MethodName: "Revoke",
+			Handler:    _Jwt_Revoke_Handler,
+		},
+		{
+			MethodName: "RevokeSubject",
+			Handler:    _Jwt_RevokeSubject_Handler,
+		},
+		{
+			MethodName: "ListSubjectCutoffs",
+			Handler:    _Jwt_ListSubjectCutoffs_Handler,
+		},
+		{
+			MethodName: "ClearSubjectCutoff",
+			Handler:    _Jwt_ClearSubjectCutoff_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "jwts_v1/jwt.proto",